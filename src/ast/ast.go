@@ -2,6 +2,8 @@ package ast
 
 import (
 	"bytes"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/psidh/Ganges/src/token"
@@ -10,6 +12,8 @@ import (
 type Node interface {
 	TokenLiteral() string
 	String() string
+	Pos() token.Position // position of the first character of the node
+	End() token.Position // position just past the last character of the node
 }
 
 type Statement interface {
@@ -25,8 +29,21 @@ type Expression interface {
 // root node
 type Program struct {
 	Statements []Statement
+	Comments   []*Comment // every comment in the source, in order
 }
 
+// Comment is a // or /* */ comment. It is not part of any statement but
+// is kept on the Program so tools like formatters can put it back.
+type Comment struct {
+	Token token.Token // the token.COMMENT token
+	Text  string      // the comment including its delimiters
+}
+
+func (c *Comment) TokenLiteral() string { return c.Token.Literal }
+func (c *Comment) String() string       { return c.Text }
+func (c *Comment) Pos() token.Position  { return c.Token.Pos }
+func (c *Comment) End() token.Position  { return c.Token.End }
+
 // This Program node is going to be the root node of every AST our parser produces
 func (p *Program) TokenLiteral() string {
 	if (len(p.Statements)) > 0 {
@@ -36,6 +53,20 @@ func (p *Program) TokenLiteral() string {
 	}
 }
 
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return token.Position{}
+}
+
+func (p *Program) End() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[len(p.Statements)-1].End()
+	}
+	return token.Position{}
+}
+
 func (p *Program) String() string {
 	var out bytes.Buffer
 
@@ -50,10 +81,19 @@ type Identifier struct {
 	Value string
 }
 
+// RamaStatement declares Name, or when destructuring every name in
+// Pattern, an array or hash literal of patterns as used by milana
 type RamaStatement struct {
-	Token token.Token // the token.RAMA token
+	Token   token.Token // the token.RAMA token
+	Name    *Identifier
+	Pattern Expression // nil unless destructuring, then Name is nil
+	Value   Expression
+}
+
+// RestElement is ...name, which takes the remaining elements of an array
+type RestElement struct {
+	Token token.Token // the ... token
 	Name  *Identifier
-	Value Expression
 }
 
 type ReturnStatement struct {
@@ -61,6 +101,16 @@ type ReturnStatement struct {
 	ReturnValue Expression
 }
 
+// BreakStatement is viram, which leaves the innermost loop
+type BreakStatement struct {
+	Token token.Token
+}
+
+// ContinueStatement is agla, which skips to the next pass of the innermost loop
+type ContinueStatement struct {
+	Token token.Token
+}
+
 type Boolean struct {
 	Token token.Token
 	Value bool
@@ -69,22 +119,39 @@ type Boolean struct {
 type BlockStatement struct {
 	Token      token.Token
 	Statements []Statement
+	Closing    token.Token // the } token
 }
 type IfExpression struct {
 	Token       token.Token
 	Condition   Expression
 	Consequence *BlockStatement
+	ElseIfs     []*ElseIf // anyatha yadi branches, tried in order
 	Alternative *BlockStatement
 }
+
+// ElseIf is one anyatha yadi (condition) { ... } branch of an IfExpression
+type ElseIf struct {
+	Token       token.Token // the anyatha token
+	Condition   Expression
+	Consequence *BlockStatement
+}
+
+// FunctionLiteral is kriya(params) { body }, or the arrow function
+// (params) => body. The body of an arrow function that is an expression
+// is kept as a block that returns it.
 type FunctionLiteral struct {
-	Token      token.Token
+	Token      token.Token // the kriya token, or the ( of an arrow function
+	Name       string      // the name it is declared with in rama, empty if anonymous
 	Parameters []*Identifier
+	Defaults   map[string]Expression // default values of optional parameters by name
+	Rest       *Identifier           // the ...rest parameter, nil if there is none
 	Body       *BlockStatement
 }
 type CallExpression struct {
 	Token     token.Token
 	Function  Expression
 	Arguments []Expression
+	Closing   token.Token // the ) token
 }
 type ExpressionStatement struct {
 	Token      token.Token
@@ -96,6 +163,11 @@ type IntegerLiteral struct {
 	Value int64
 }
 
+type FloatLiteral struct {
+	Token token.Token
+	Value float64
+}
+
 type PrefixExpression struct {
 	Token    token.Token
 	Operator string
@@ -114,20 +186,96 @@ type StringLiteral struct {
 	Value string
 }
 
+// InterpolatedString is a string with embedded ${...} expressions. Parts
+// holds the text pieces as *StringLiteral with the expressions in between.
+type InterpolatedString struct {
+	Token   token.Token // the token.VAKYA_HEAD token
+	Parts   []Expression
+	Closing token.Token // the token.VAKYA_TAIL token
+}
+
 type ArrayLiteral struct {
 	Token    token.Token
 	Elements []Expression
+	Closing  token.Token // the ] token
 }
 
 type HashLiteral struct {
-	Token token.Token
-	Pairs map[Expression]Expression
+	Token   token.Token
+	Pairs   map[Expression]Expression
+	Closing token.Token // the } token
 }
 
 type IndexExpression struct {
-	Token token.Token // The [ token
-	Left  Expression
-	Index Expression
+	Token   token.Token // The [ token
+	Left    Expression
+	Index   Expression
+	Closing token.Token // the ] token
+}
+
+// MemberExpression is object.property, a field of a hash with a string
+// key, or a method of the value when it is called as object.method(args)
+type MemberExpression struct {
+	Token    token.Token // The . token
+	Object   Expression
+	Property *Identifier
+}
+
+// SliceExpression is left[low:high]. Low and High are nil when they are
+// left out, as in xs[:n] or xs[2:].
+type SliceExpression struct {
+	Token   token.Token // The [ token
+	Left    Expression
+	Low     Expression
+	High    Expression
+	Closing token.Token // the ] token
+}
+
+// AssignExpression is target = value for an already declared target, or
+// a compound assignment such as target += value
+type AssignExpression struct {
+	Token    token.Token // the = or op= token
+	Target   Expression
+	Operator string
+	Value    Expression
+}
+
+// MatchExpression is milana (subject) { pattern => value, ... }. The value
+// of the first arm whose pattern matches, and whose guard holds, is the
+// value of the whole expression.
+type MatchExpression struct {
+	Token   token.Token // the milana token
+	Subject Expression
+	Arms    []*MatchArm
+	Closing token.Token // the } token
+}
+
+// MatchArm is pattern => body, or pattern yadi guard => body. A pattern is
+// a literal, a name to bind, _ to match anything, or an array or hash
+// literal made of patterns.
+type MatchArm struct {
+	Token   token.Token // the => token
+	Pattern Expression
+	Guard   Expression // nil when there is no yadi
+	Body    Expression
+}
+
+// RangeExpression is from..to, or from..<to without to itself, with an
+// optional kadam step
+type RangeExpression struct {
+	Token     token.Token // the .. or ..< token
+	From      Expression
+	To        Expression
+	Inclusive bool
+	Step      Expression // nil when there is no kadam
+}
+
+// UpdateExpression is ++ or -- before or after its target
+type UpdateExpression struct {
+	Token    token.Token // the ++ or -- token
+	Operator string
+	Target   Expression
+	Prefix   bool
 }
 
 type ChakraStatement struct {
@@ -136,8 +284,44 @@ type ChakraStatement struct {
 	Body      *BlockStatement
 }
 
+// PratyekStatement is pratyek (x : items) { ... }, or pratyek (k, v : items)
+// to also bind the key or index of each item
+type PratyekStatement struct {
+	Token     token.Token
+	Variables []*Identifier
+	Iterable  Expression
+	Body      *BlockStatement
+}
+
+func (ps *PratyekStatement) statementNode()       {}
+func (ps *PratyekStatement) TokenLiteral() string { return ps.Token.Literal }
+func (ps *PratyekStatement) Pos() token.Position  { return ps.Token.Pos }
+func (ps *PratyekStatement) End() token.Position  { return blockEnd(ps.Body, ps.Token) }
+func (ps *PratyekStatement) String() string {
+	var out bytes.Buffer
+
+	variables := []string{}
+	for _, v := range ps.Variables {
+		variables = append(variables, v.String())
+	}
+
+	out.WriteString("pratyek")
+	out.WriteString("(")
+	out.WriteString(strings.Join(variables, ", "))
+	out.WriteString(" : ")
+	out.WriteString(ps.Iterable.String())
+	out.WriteString(")")
+	out.WriteString("{")
+	out.WriteString(ps.Body.String())
+	out.WriteString("}")
+
+	return out.String()
+}
+
 func (w *ChakraStatement) statementNode()       {}
 func (w *ChakraStatement) TokenLiteral() string { return w.Token.Literal }
+func (w *ChakraStatement) Pos() token.Position  { return w.Token.Pos }
+func (w *ChakraStatement) End() token.Position  { return blockEnd(w.Body, w.Token) }
 func (w *ChakraStatement) String() string {
 	var out bytes.Buffer
 
@@ -152,8 +336,115 @@ func (w *ChakraStatement) String() string {
 	return out.String()
 }
 
+func (ae *AssignExpression) expressionNode()      {}
+func (ae *AssignExpression) TokenLiteral() string { return ae.Token.Literal }
+func (ae *AssignExpression) Pos() token.Position  { return posOf(ae.Target, ae.Token) }
+func (ae *AssignExpression) End() token.Position  { return endOf(ae.Value, ae.Token) }
+func (ae *AssignExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(ae.Target.String())
+	out.WriteString(" " + ae.Operator + " ")
+	if ae.Value != nil {
+		out.WriteString(ae.Value.String())
+	}
+	out.WriteString(")")
+
+	return out.String()
+}
+
+func (re *RestElement) expressionNode()      {}
+func (re *RestElement) TokenLiteral() string { return re.Token.Literal }
+func (re *RestElement) Pos() token.Position  { return re.Token.Pos }
+func (re *RestElement) End() token.Position  { return endOf(re.Name, re.Token) }
+func (re *RestElement) String() string       { return "..." + re.Name.String() }
+
+func (me *MatchExpression) expressionNode()      {}
+func (me *MatchExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MatchExpression) Pos() token.Position  { return me.Token.Pos }
+func (me *MatchExpression) End() token.Position  { return closingEnd(me.Closing, me.Token) }
+func (me *MatchExpression) String() string {
+	var out bytes.Buffer
+
+	arms := []string{}
+	for _, arm := range me.Arms {
+		arms = append(arms, arm.String())
+	}
+
+	out.WriteString("milana")
+	out.WriteString(me.Subject.String())
+	out.WriteString(" { ")
+	out.WriteString(strings.Join(arms, ", "))
+	out.WriteString(" }")
+
+	return out.String()
+}
+
+func (ma *MatchArm) String() string {
+	var out bytes.Buffer
+
+	out.WriteString(ma.Pattern.String())
+	if ma.Guard != nil {
+		out.WriteString(" yadi ")
+		out.WriteString(ma.Guard.String())
+	}
+	out.WriteString(" => ")
+	out.WriteString(ma.Body.String())
+
+	return out.String()
+}
+
+func (re *RangeExpression) expressionNode()      {}
+func (re *RangeExpression) TokenLiteral() string { return re.Token.Literal }
+func (re *RangeExpression) Pos() token.Position  { return posOf(re.From, re.Token) }
+func (re *RangeExpression) End() token.Position {
+	if re.Step != nil {
+		return re.Step.End()
+	}
+	return endOf(re.To, re.Token)
+}
+func (re *RangeExpression) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("(")
+	out.WriteString(re.From.String())
+	out.WriteString(re.Token.Literal)
+	out.WriteString(re.To.String())
+	if re.Step != nil {
+		out.WriteString(" kadam ")
+		out.WriteString(re.Step.String())
+	}
+	out.WriteString(")")
+
+	return out.String()
+}
+
+func (ue *UpdateExpression) expressionNode()      {}
+func (ue *UpdateExpression) TokenLiteral() string { return ue.Token.Literal }
+func (ue *UpdateExpression) Pos() token.Position {
+	if ue.Prefix {
+		return ue.Token.Pos
+	}
+	return posOf(ue.Target, ue.Token)
+}
+func (ue *UpdateExpression) End() token.Position {
+	if ue.Prefix {
+		return endOf(ue.Target, ue.Token)
+	}
+	return ue.Token.End
+}
+func (ue *UpdateExpression) String() string {
+	if ue.Prefix {
+		return "(" + ue.Operator + ue.Target.String() + ")"
+	}
+	return "(" + ue.Target.String() + ue.Operator + ")"
+}
+
 func (ie *IndexExpression) expressionNode()      {}
 func (ie *IndexExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IndexExpression) Pos() token.Position  { return posOf(ie.Left, ie.Token) }
+func (ie *IndexExpression) End() token.Position  { return closingEnd(ie.Closing, ie.Token) }
 func (ie *IndexExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -164,29 +455,103 @@ func (ie *IndexExpression) String() string {
 	return out.String()
 }
 
+func (me *MemberExpression) expressionNode()      {}
+func (me *MemberExpression) TokenLiteral() string { return me.Token.Literal }
+func (me *MemberExpression) Pos() token.Position  { return posOf(me.Object, me.Token) }
+func (me *MemberExpression) End() token.Position  { return me.Property.End() }
+func (me *MemberExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(me.Object.String())
+	out.WriteString(".")
+	out.WriteString(me.Property.String())
+	out.WriteString(")")
+	return out.String()
+}
+
+func (se *SliceExpression) expressionNode()      {}
+func (se *SliceExpression) TokenLiteral() string { return se.Token.Literal }
+func (se *SliceExpression) Pos() token.Position  { return posOf(se.Left, se.Token) }
+func (se *SliceExpression) End() token.Position  { return closingEnd(se.Closing, se.Token) }
+func (se *SliceExpression) String() string {
+	var out bytes.Buffer
+	out.WriteString("(")
+	out.WriteString(se.Left.String())
+	out.WriteString("[")
+	if se.Low != nil {
+		out.WriteString(se.Low.String())
+	}
+	out.WriteString(":")
+	if se.High != nil {
+		out.WriteString(se.High.String())
+	}
+	out.WriteString("])")
+	return out.String()
+}
+
 func (ls *RamaStatement) statementNode()       {}
 func (ls *RamaStatement) TokenLiteral() string { return ls.Token.Literal }
+func (ls *RamaStatement) Pos() token.Position  { return ls.Token.Pos }
+func (ls *RamaStatement) End() token.Position  { return endOf(ls.Value, ls.Token) }
 
 func (i *Identifier) expressionNode()      {}
 func (i *Identifier) TokenLiteral() string { return i.Token.Literal }
+func (i *Identifier) Pos() token.Position  { return i.Token.Pos }
+func (i *Identifier) End() token.Position  { return i.Token.End }
 func (i *Identifier) String() string       { return i.Value }
 
 func (rs *ReturnStatement) statementNode()       {}
 func (rs *ReturnStatement) TokenLiteral() string { return rs.Token.Literal }
+func (rs *ReturnStatement) Pos() token.Position  { return rs.Token.Pos }
+func (rs *ReturnStatement) End() token.Position  { return endOf(rs.ReturnValue, rs.Token) }
+
+func (bs *BreakStatement) statementNode()       {}
+func (bs *BreakStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BreakStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BreakStatement) End() token.Position  { return bs.Token.End }
+func (bs *BreakStatement) String() string       { return bs.Token.Literal + ";" }
+
+func (cs *ContinueStatement) statementNode()       {}
+func (cs *ContinueStatement) TokenLiteral() string { return cs.Token.Literal }
+func (cs *ContinueStatement) Pos() token.Position  { return cs.Token.Pos }
+func (cs *ContinueStatement) End() token.Position  { return cs.Token.End }
+func (cs *ContinueStatement) String() string       { return cs.Token.Literal + ";" }
 
 func (b *Boolean) expressionNode()      {}
 func (b *Boolean) TokenLiteral() string { return b.Token.Literal }
+func (b *Boolean) Pos() token.Position  { return b.Token.Pos }
+func (b *Boolean) End() token.Position  { return b.Token.End }
 func (b *Boolean) String() string       { return b.Token.Literal }
 
 func (es *ExpressionStatement) statementNode()       {}
 func (es *ExpressionStatement) TokenLiteral() string { return es.Token.Literal }
+func (es *ExpressionStatement) Pos() token.Position  { return posOf(es.Expression, es.Token) }
+func (es *ExpressionStatement) End() token.Position  { return endOf(es.Expression, es.Token) }
 
 func (il *IntegerLiteral) expressionNode()      {}
 func (il *IntegerLiteral) TokenLiteral() string { return il.Token.Literal }
-func (il *IntegerLiteral) String() string       { return il.Token.Literal }
+func (il *IntegerLiteral) Pos() token.Position  { return il.Token.Pos }
+func (il *IntegerLiteral) End() token.Position  { return il.Token.End }
+
+// String keeps the literal as written (0xff stays 0xff) and only falls
+// back to decimal for literals built without a token.
+func (il *IntegerLiteral) String() string {
+	if il.Token.Literal == "" {
+		return strconv.FormatInt(il.Value, 10)
+	}
+	return il.Token.Literal
+}
+
+func (fl *FloatLiteral) expressionNode()      {}
+func (fl *FloatLiteral) TokenLiteral() string { return fl.Token.Literal }
+func (fl *FloatLiteral) Pos() token.Position  { return fl.Token.Pos }
+func (fl *FloatLiteral) End() token.Position  { return fl.Token.End }
+func (fl *FloatLiteral) String() string       { return fl.Token.Literal }
 
 func (pe *PrefixExpression) expressionNode()      {}
 func (pe *PrefixExpression) TokenLiteral() string { return pe.Token.Literal }
+func (pe *PrefixExpression) Pos() token.Position  { return pe.Token.Pos }
+func (pe *PrefixExpression) End() token.Position  { return endOf(pe.Right, pe.Token) }
 func (pe *PrefixExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("(")
@@ -198,6 +563,8 @@ func (pe *PrefixExpression) String() string {
 
 func (oe *InfixExpression) expressionNode()      {}
 func (oe *InfixExpression) TokenLiteral() string { return oe.Token.Literal }
+func (oe *InfixExpression) Pos() token.Position  { return posOf(oe.Left, oe.Token) }
+func (oe *InfixExpression) End() token.Position  { return endOf(oe.Right, oe.Token) }
 func (oe *InfixExpression) String() string {
 	var out bytes.Buffer
 
@@ -211,6 +578,16 @@ func (oe *InfixExpression) String() string {
 
 func (ie *IfExpression) expressionNode()      {}
 func (ie *IfExpression) TokenLiteral() string { return ie.Token.Literal }
+func (ie *IfExpression) Pos() token.Position  { return ie.Token.Pos }
+func (ie *IfExpression) End() token.Position {
+	if ie.Alternative != nil {
+		return ie.Alternative.End()
+	}
+	if n := len(ie.ElseIfs); n > 0 {
+		return blockEnd(ie.ElseIfs[n-1].Consequence, ie.ElseIfs[n-1].Token)
+	}
+	return blockEnd(ie.Consequence, ie.Token)
+}
 func (ie *IfExpression) String() string {
 	var out bytes.Buffer
 	out.WriteString("if")
@@ -218,6 +595,13 @@ func (ie *IfExpression) String() string {
 	out.WriteString(" ")
 	out.WriteString(ie.Consequence.String())
 
+	for _, branch := range ie.ElseIfs {
+		out.WriteString("else if")
+		out.WriteString(branch.Condition.String())
+		out.WriteString(" ")
+		out.WriteString(branch.Consequence.String())
+	}
+
 	if ie.Alternative != nil {
 		out.WriteString("else ")
 		out.WriteString(ie.Alternative.String())
@@ -228,6 +612,14 @@ func (ie *IfExpression) String() string {
 
 func (bs *BlockStatement) statementNode()       {}
 func (bs *BlockStatement) TokenLiteral() string { return bs.Token.Literal }
+func (bs *BlockStatement) Pos() token.Position  { return bs.Token.Pos }
+func (bs *BlockStatement) End() token.Position {
+	// the body of an arrow function may have no braces
+	if !bs.Closing.End.IsValid() && len(bs.Statements) > 0 {
+		return bs.Statements[len(bs.Statements)-1].End()
+	}
+	return closingEnd(bs.Closing, bs.Token)
+}
 func (bs *BlockStatement) String() string {
 	var out bytes.Buffer
 
@@ -239,13 +631,30 @@ func (bs *BlockStatement) String() string {
 
 func (fn *FunctionLiteral) expressionNode()      {}
 func (fn *FunctionLiteral) TokenLiteral() string { return fn.Token.Literal }
+func (fn *FunctionLiteral) Pos() token.Position  { return fn.Token.Pos }
+func (fn *FunctionLiteral) End() token.Position  { return blockEnd(fn.Body, fn.Token) }
 func (fn *FunctionLiteral) String() string {
 	var out bytes.Buffer
 
 	params := []string{}
 
 	for _, p := range fn.Parameters {
-		params = append(params, p.String())
+		if value, ok := fn.Defaults[p.Value]; ok {
+			params = append(params, p.String()+" = "+value.String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if fn.Rest != nil {
+		params = append(params, "..."+fn.Rest.String())
+	}
+
+	if fn.Token.Type == token.LPAREN {
+		out.WriteString("(")
+		out.WriteString(strings.Join(params, ", "))
+		out.WriteString(") => ")
+		out.WriteString(fn.Body.String())
+		return out.String()
 	}
 
 	out.WriteString(fn.TokenLiteral())
@@ -258,6 +667,8 @@ func (fn *FunctionLiteral) String() string {
 
 func (ce *CallExpression) expressionNode()      {}
 func (ce *CallExpression) TokenLiteral() string { return ce.Token.Literal }
+func (ce *CallExpression) Pos() token.Position  { return posOf(ce.Function, ce.Token) }
+func (ce *CallExpression) End() token.Position  { return closingEnd(ce.Closing, ce.Token) }
 func (ce *CallExpression) String() string {
 	var out bytes.Buffer
 
@@ -277,12 +688,38 @@ func (ce *CallExpression) String() string {
 
 func (sl *StringLiteral) expressionNode()      {}
 func (sl *StringLiteral) TokenLiteral() string { return sl.Token.Literal }
+func (sl *StringLiteral) Pos() token.Position  { return sl.Token.Pos }
+func (sl *StringLiteral) End() token.Position  { return sl.Token.End }
 func (sl *StringLiteral) String() string       { return sl.Token.Literal }
 
+func (is *InterpolatedString) expressionNode()      {}
+func (is *InterpolatedString) TokenLiteral() string { return is.Token.Literal }
+func (is *InterpolatedString) Pos() token.Position  { return is.Token.Pos }
+func (is *InterpolatedString) End() token.Position  { return closingEnd(is.Closing, is.Token) }
+func (is *InterpolatedString) String() string {
+	var out bytes.Buffer
+
+	out.WriteString("\"")
+	for _, part := range is.Parts {
+		if text, ok := part.(*StringLiteral); ok {
+			out.WriteString(text.Value)
+		} else {
+			out.WriteString("${" + part.String() + "}")
+		}
+	}
+	out.WriteString("\"")
+
+	return out.String()
+}
+
 func (ls *RamaStatement) String() string {
 	var out bytes.Buffer
 	out.WriteString(ls.TokenLiteral() + " ")
-	out.WriteString(ls.Name.String())
+	if ls.Pattern != nil {
+		out.WriteString(ls.Pattern.String())
+	} else {
+		out.WriteString(ls.Name.String())
+	}
 	out.WriteString(" = ")
 	if ls.Value != nil {
 		out.WriteString(ls.Value.String())
@@ -294,6 +731,8 @@ func (ls *RamaStatement) String() string {
 
 func (al *ArrayLiteral) expressionNode()      {}
 func (al *ArrayLiteral) TokenLiteral() string { return al.Token.Literal }
+func (al *ArrayLiteral) Pos() token.Position  { return al.Token.Pos }
+func (al *ArrayLiteral) End() token.Position  { return closingEnd(al.Closing, al.Token) }
 func (al *ArrayLiteral) String() string {
 	var out bytes.Buffer
 
@@ -312,6 +751,11 @@ func (al *ArrayLiteral) String() string {
 func (rs *ReturnStatement) String() string {
 	var out bytes.Buffer
 
+	// the implicit return of an arrow function body is just its value
+	if rs.Token.Type == token.ARROW && rs.ReturnValue != nil {
+		return rs.ReturnValue.String()
+	}
+
 	out.WriteString(rs.TokenLiteral() + " ")
 
 	if rs.ReturnValue != nil {
@@ -333,16 +777,56 @@ func (es *ExpressionStatement) String() string {
 
 func (hl *HashLiteral) expressionNode()      {}
 func (hl *HashLiteral) TokenLiteral() string { return hl.Token.Literal }
+func (hl *HashLiteral) Pos() token.Position  { return hl.Token.Pos }
+func (hl *HashLiteral) End() token.Position  { return closingEnd(hl.Closing, hl.Token) }
 
+// String writes the pairs in the order their keys appear in the source,
+// which the map doesn't keep
 func (hl *HashLiteral) String() string {
 	var out bytes.Buffer
-	pairs := []string{}
-	for key, value := range hl.Pairs {
-		pairs = append(pairs, key.String()+":"+value.String())
-		out.WriteString("{")
-		out.WriteString(strings.Join(pairs, ", "))
-		out.WriteString("}")
 
+	keys := make([]Expression, 0, len(hl.Pairs))
+	for key := range hl.Pairs {
+		keys = append(keys, key)
 	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].Pos().Offset < keys[j].Pos().Offset })
+
+	pairs := []string{}
+	for _, key := range keys {
+		pairs = append(pairs, key.String()+":"+hl.Pairs[key].String())
+	}
+	out.WriteString("{")
+	out.WriteString(strings.Join(pairs, ", "))
+	out.WriteString("}")
 	return out.String()
 }
+
+// posOf and endOf fall back to tok when a child node is missing, which
+// happens for nodes built from input with parse errors.
+func posOf(n Node, tok token.Token) token.Position {
+	if n != nil {
+		return n.Pos()
+	}
+	return tok.Pos
+}
+
+func endOf(n Node, tok token.Token) token.Position {
+	if n != nil {
+		return n.End()
+	}
+	return tok.End
+}
+
+func blockEnd(b *BlockStatement, tok token.Token) token.Position {
+	if b != nil {
+		return b.End()
+	}
+	return tok.End
+}
+
+func closingEnd(closing, tok token.Token) token.Position {
+	if closing.End.IsValid() {
+		return closing.End
+	}
+	return tok.End
+}
@@ -26,3 +26,20 @@ func TestString(t *testing.T) {
 		t.Errorf("program.String() wrong. got=%q", program.String())
 	}
 }
+
+func TestIntegerLiteralString(t *testing.T) {
+	tests := []struct {
+		literal  *IntegerLiteral
+		expected string
+	}{
+		{&IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "0xff"}, Value: 255}, "0xff"},
+		{&IntegerLiteral{Token: token.Token{Type: token.INT, Literal: "1_000"}, Value: 1000}, "1_000"},
+		{&IntegerLiteral{Value: 42}, "42"},
+	}
+
+	for _, tt := range tests {
+		if tt.literal.String() != tt.expected {
+			t.Errorf("String() wrong. expected=%q, got=%q", tt.expected, tt.literal.String())
+		}
+	}
+}
@@ -2,6 +2,7 @@ package eval
 
 import (
 	"fmt"
+	"unicode/utf8"
 
 	"github.com/psidh/Ganges/src/object"
 )
@@ -15,9 +16,12 @@ var builtins = map[string]*object.Builtin{
 			}
 			switch arg := args[0].(type) {
 			case *object.String:
-				return &object.Integer{Value: int64(len(arg.Value))}
+				// characters rather than bytes, the way strings are indexed
+				return &object.Integer{Value: int64(utf8.RuneCountInString(arg.Value))}
 			case *object.Array:
 				return &object.Integer{Value: int64(len(arg.Elements))}
+			case *object.Range:
+				return &object.Integer{Value: arg.Len()}
 			default:
 				return newError("argument to `dairghya` not supported, got %s",
 					args[0].Type())
@@ -114,9 +118,14 @@ var builtins = map[string]*object.Builtin{
 				return newError("wrong number of arguments. got=%d, want=2", len(args))
 			}
 
+			if r, ok := args[0].(*object.Range); ok {
+				n, ok := args[1].(*object.Integer)
+				return nativeBoolToBooleanObject(ok && r.Contains(n.Value))
+			}
+
 			setObj, ok := args[0].(*object.Set)
 			if !ok {
-				return newError("first argument must be SET, got %s", args[0].Type())
+				return newError("first argument must be SET or RANGE, got %s", args[0].Type())
 			}
 
 			hashable, ok := args[1].(object.Hashable)
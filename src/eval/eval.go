@@ -1,13 +1,26 @@
 package eval
 
 import (
+	"bytes"
 	"fmt"
+	"math"
+	"strings"
 
 	"github.com/psidh/Ganges/src/ast"
 	"github.com/psidh/Ganges/src/object"
 )
 
 func Eval(node ast.Node, env *object.Environment) object.Object {
+	result := evalNode(node, env)
+
+	// the innermost node that produced an error is the best place to point at
+	if err, ok := result.(*object.Error); ok && !err.Pos.IsValid() {
+		err.Pos = node.Pos()
+	}
+	return result
+}
+
+func evalNode(node ast.Node, env *object.Environment) object.Object {
 	switch node := node.(type) {
 
 	case *ast.Program:
@@ -16,21 +29,26 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 		return Eval(node.Expression, env)
 	case *ast.IntegerLiteral:
 		return &object.Integer{Value: node.Value}
+	case *ast.FloatLiteral:
+		return &object.Float{Value: node.Value}
 	case *ast.Boolean:
 		return nativeBoolToBooleanObject(node.Value)
 	case *ast.PrefixExpression:
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if isAbrupt(right) {
 			return right
 		}
 		return evalPrefixExpression(node.Operator, right)
 	case *ast.InfixExpression:
+		if node.Operator == "&&" || node.Operator == "||" {
+			return evalLogicalExpression(node, env)
+		}
 		left := Eval(node.Left, env)
-		if isError(left) {
+		if isAbrupt(left) {
 			return left
 		}
 		right := Eval(node.Right, env)
-		if isError(right) {
+		if isAbrupt(right) {
 			return right
 		}
 		return evalInfixExpression(left, node.Operator, right)
@@ -39,57 +57,97 @@ func Eval(node ast.Node, env *object.Environment) object.Object {
 	case *ast.IfExpression:
 		return evalIfExpression(node, env)
 	case *ast.ReturnStatement:
+		if node.ReturnValue == nil {
+			return &object.ReturnValue{Value: NULL}
+		}
 		val := Eval(node.ReturnValue, env)
-		if isError(val) {
+		if isAbrupt(val) {
 			return val
 		}
 		return &object.ReturnValue{Value: val}
 	case *ast.RamaStatement:
 		val := Eval(node.Value, env)
-		if isError(val) {
+		if isAbrupt(val) {
 			return val
 		}
+		if node.Pattern != nil {
+			if mismatch := matchPattern(node.Pattern, val, env); mismatch != "" {
+				return newError("cannot destructure %s into %s: %s", val.Type(), node.Pattern, mismatch)
+			}
+			return NULL
+		}
 		env.Set(node.Name.Value, val)
 	case *ast.Identifier:
 		return evalIdentifier(node, env)
+	case *ast.AssignExpression:
+		return evalAssignExpression(node, env)
+	case *ast.RestElement:
+		return newError("%s is only allowed in patterns", node)
+	case *ast.MatchExpression:
+		return evalMatchExpression(node, env)
+	case *ast.RangeExpression:
+		return evalRangeExpression(node, env)
+	case *ast.UpdateExpression:
+		return evalUpdateExpression(node, env)
 	case *ast.FunctionLiteral:
-		params := node.Parameters
-		body := node.Body
-		return &object.Function{Parameters: params, Env: env, Body: body}
+		return &object.Function{
+			Name:       node.Name,
+			Parameters: node.Parameters,
+			Defaults:   node.Defaults,
+			Rest:       node.Rest,
+			Env:        env,
+			Body:       node.Body,
+		}
 	case *ast.CallExpression:
-		function := Eval(node.Function, env)
-		if isError(function) {
+		function := evalCallee(node.Function, env)
+		if isAbrupt(function) {
 			return function
 		}
 		args := evalExpressions(node.Arguments, env)
-		if len(args) == 1 && isError(args[0]) {
+		if len(args) == 1 && isAbrupt(args[0]) {
 			return args[0]
 		}
 		return applyFunction(function, args)
 	case *ast.StringLiteral:
 		return &object.String{Value: node.Value}
+	case *ast.InterpolatedString:
+		return evalInterpolatedString(node, env)
 	case *ast.ArrayLiteral:
 		elements := evalExpressions(node.Elements, env)
 
-		if len(elements) == 1 && isError(elements[0]) {
+		if len(elements) == 1 && isAbrupt(elements[0]) {
 			return elements[0]
 		}
 
 		return &object.Array{Elements: elements}
 	case *ast.IndexExpression:
 		left := Eval(node.Left, env)
-		if isError(left) {
+		if isAbrupt(left) {
 			return left
 		}
 		index := Eval(node.Index, env)
-		if isError(index) {
+		if isAbrupt(index) {
 			return index
 		}
 		return evalIndexExpression(left, index)
+	case *ast.SliceExpression:
+		return evalSliceExpression(node, env)
+	case *ast.MemberExpression:
+		receiver := Eval(node.Object, env)
+		if isAbrupt(receiver) {
+			return receiver
+		}
+		return evalMemberExpression(receiver, node.Property.Value)
 	case *ast.HashLiteral:
 		return evalHashLiteral(node, env)
 	case *ast.ChakraStatement:
 		return evalChakraExpression(node, env)
+	case *ast.PratyekStatement:
+		return evalPratyekStatement(node, env)
+	case *ast.BreakStatement:
+		return BREAK
+	case *ast.ContinueStatement:
+		return CONTINUE
 	}
 	return NULL
 }
@@ -100,7 +158,7 @@ func evalExpressions(expression []ast.Expression, env *object.Environment) []obj
 	for _, e := range expression {
 		evaluated := Eval(e, env)
 
-		if isError(evaluated) {
+		if isAbrupt(evaluated) {
 			return []object.Object{evaluated}
 		}
 
@@ -134,7 +192,8 @@ func evalBlockStatement(block *ast.BlockStatement, env *object.Environment) obje
 		if result != nil {
 
 			rt := result.Type()
-			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ ||
+				rt == object.BREAK_OBJ || rt == object.CONTINUE_OBJ {
 				return result
 			}
 		}
@@ -176,24 +235,30 @@ func evalBangOperatorExpression(right object.Object) object.Object {
 }
 
 func evalMinusOperatorExpression(right object.Object) object.Object {
-	if right.Type() != object.INTEGER_OBJ {
+	switch right := right.(type) {
+	case *object.Integer:
+		return &object.Integer{Value: right.Value * -1}
+	case *object.Float:
+		return &object.Float{Value: right.Value * -1}
+	default:
 		return newError("unknown operator: -%s", right.Type())
 	}
-
-	literalValue := right.(*object.Integer).Value
-	return &object.Integer{Value: literalValue * -1}
 }
 
 var (
-	NULL   = &object.Null{}
-	SATYA  = &object.Boolean{Value: true}
-	ASATYA = &object.Boolean{Value: false}
+	NULL     = &object.Null{}
+	SATYA    = &object.Boolean{Value: true}
+	ASATYA   = &object.Boolean{Value: false}
+	BREAK    = &object.Break{}
+	CONTINUE = &object.Continue{}
 )
 
 func evalInfixExpression(left object.Object, operator string, right object.Object) object.Object {
 	switch {
 	case left.Type() == object.INTEGER_OBJ && right.Type() == object.INTEGER_OBJ:
 		return evalIntegerInfixExpression(operator, left, right)
+	case isNumber(left) && isNumber(right):
+		return evalFloatInfixExpression(operator, left, right)
 	case operator == "==" && left.Type() == object.STRING_OBJ && right.Type() == object.STRING_OBJ:
 		leftVal := left.(*object.String).Value
 		rightVal := right.(*object.String).Value
@@ -223,7 +288,48 @@ func evalIntegerInfixExpression(operator string, left object.Object, right objec
 	case "*":
 		return &object.Integer{Value: leftValue * rightValue}
 	case "/":
+		if rightValue == 0 {
+			return newError("division by zero: %d / 0", leftValue)
+		}
 		return &object.Integer{Value: leftValue / rightValue}
+	case "%":
+		if rightValue == 0 {
+			return newError("division by zero: %d %% 0", leftValue)
+		}
+		return &object.Integer{Value: leftValue % rightValue}
+	case "<":
+		return nativeBoolToBooleanObject(leftValue < rightValue)
+	case ">":
+		return nativeBoolToBooleanObject(leftValue > rightValue)
+	case ">=":
+		return nativeBoolToBooleanObject(leftValue >= rightValue)
+	case "<=":
+		return nativeBoolToBooleanObject(leftValue <= rightValue)
+	case "==":
+		return nativeBoolToBooleanObject(leftValue == rightValue)
+	case "!=":
+		return nativeBoolToBooleanObject(leftValue != rightValue)
+	default:
+		return newError("unknown operator: %s %s %s", left.Type(), operator, right.Type())
+	}
+}
+
+// evalFloatInfixExpression handles two floats or a float mixed with an integer
+func evalFloatInfixExpression(operator string, left object.Object, right object.Object) object.Object {
+	leftValue := toFloat(left)
+	rightValue := toFloat(right)
+
+	switch operator {
+	case "+":
+		return &object.Float{Value: leftValue + rightValue}
+	case "-":
+		return &object.Float{Value: leftValue - rightValue}
+	case "*":
+		return &object.Float{Value: leftValue * rightValue}
+	case "/":
+		return &object.Float{Value: leftValue / rightValue}
+	case "%":
+		return &object.Float{Value: math.Mod(leftValue, rightValue)}
 	case "<":
 		return nativeBoolToBooleanObject(leftValue < rightValue)
 	case ">":
@@ -241,20 +347,67 @@ func evalIntegerInfixExpression(operator string, left object.Object, right objec
 	}
 }
 
+// evalLogicalExpression evaluates && and ||. The right side is only
+// evaluated when the left side doesn't already decide the result.
+func evalLogicalExpression(node *ast.InfixExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+
+	if node.Operator == "&&" && !isTruthy(left) {
+		return ASATYA
+	}
+	if node.Operator == "||" && isTruthy(left) {
+		return SATYA
+	}
+
+	right := Eval(node.Right, env)
+	if isAbrupt(right) {
+		return right
+	}
+	return nativeBoolToBooleanObject(isTruthy(right))
+}
+
+func isNumber(obj object.Object) bool {
+	return obj.Type() == object.INTEGER_OBJ || obj.Type() == object.FLOAT_OBJ
+}
+
+func toFloat(obj object.Object) float64 {
+	switch obj := obj.(type) {
+	case *object.Integer:
+		return float64(obj.Value)
+	case *object.Float:
+		return obj.Value
+	}
+	return 0
+}
+
 func evalIfExpression(ie *ast.IfExpression, env *object.Environment) object.Object {
 	condition := Eval(ie.Condition, env)
 
-	if isError(condition) {
+	if isAbrupt(condition) {
 		return condition
 	}
 
 	if isTruthy(condition) {
 		return Eval(ie.Consequence, env)
-	} else if ie.Alternative != nil {
+	}
+
+	for _, branch := range ie.ElseIfs {
+		condition := Eval(branch.Condition, env)
+		if isAbrupt(condition) {
+			return condition
+		}
+		if isTruthy(condition) {
+			return Eval(branch.Consequence, env)
+		}
+	}
+
+	if ie.Alternative != nil {
 		return Eval(ie.Alternative, env)
-	} else {
-		return NULL
 	}
+	return NULL
 }
 
 func isTruthy(obj object.Object) bool {
@@ -274,9 +427,13 @@ func newError(format string, a ...interface{}) *object.Error {
 	return &object.Error{Message: fmt.Sprintf(format, a...)}
 }
 
-func isError(obj object.Object) bool {
+// isAbrupt reports whether obj cuts the evaluation of an expression short.
+// Besides errors that is a viram or agla on its way out to the loop, so a
+// yadi or milana used as a value can still end the loop around it.
+func isAbrupt(obj object.Object) bool {
 	if obj != nil {
-		return obj.Type() == object.ERROR_OBJ
+		t := obj.Type()
+		return t == object.ERROR_OBJ || t == object.BREAK_OBJ || t == object.CONTINUE_OBJ
 	}
 	return false
 }
@@ -293,27 +450,292 @@ func evalIdentifier(node *ast.Identifier, env *object.Environment) object.Object
 	return newError("identifier not found: " + node.Value)
 }
 
+func evalAssignExpression(node *ast.AssignExpression, env *object.Environment) object.Object {
+	if node.Operator != "=" {
+		operator := strings.TrimSuffix(node.Operator, "=")
+		_, val := evalUpdate(node.Target, env, func(old object.Object) object.Object {
+			right := Eval(node.Value, env)
+			if isAbrupt(right) {
+				return right
+			}
+			return evalInfixExpression(old, operator, right)
+		})
+		return val
+	}
+
+	switch node.Target.(type) {
+	case *ast.IndexExpression, *ast.MemberExpression:
+		return evalIndexAssignment(node.Target, node.Value, env)
+	}
+
+	val := Eval(node.Value, env)
+	if isAbrupt(val) {
+		return val
+	}
+
+	name := node.Target.(*ast.Identifier).Value
+	if !env.Assign(name, val) {
+		return newError("assignment to undeclared variable: %s", name)
+	}
+	return val
+}
+
+// evalRangeExpression builds a Range. Nothing is allocated per element, so
+// 0..<n costs the same for any n.
+func evalRangeExpression(node *ast.RangeExpression, env *object.Environment) object.Object {
+	bounds := []ast.Expression{node.From, node.To}
+	if node.Step != nil {
+		bounds = append(bounds, node.Step)
+	}
+
+	values := []int64{}
+	for _, bound := range bounds {
+		val := Eval(bound, env)
+		if isAbrupt(val) {
+			return val
+		}
+		integer, ok := val.(*object.Integer)
+		if !ok {
+			return newError("range bounds must be INTEGER, got %s", val.Type())
+		}
+		values = append(values, integer.Value)
+	}
+
+	r := &object.Range{From: values[0], To: values[1], Step: 1, Inclusive: node.Inclusive}
+	if node.Step != nil {
+		r.Step = values[2]
+	}
+	if r.Step == 0 {
+		return newError("range step must not be 0")
+	}
+	return r
+}
+
+// evalIndexAssignment evaluates xs[i] = value, hash[key] = value and
+// hash.key = value
+func evalIndexAssignment(target ast.Expression, value ast.Expression, env *object.Environment) object.Object {
+	left, index := evalElementTarget(target, env)
+	if isAbrupt(left) {
+		return left
+	}
+	if isAbrupt(index) {
+		return index
+	}
+	val := Eval(value, env)
+	if isAbrupt(val) {
+		return val
+	}
+
+	if err := setIndex(left, index, val); err != nil {
+		return err
+	}
+	return val
+}
+
+// evalElementTarget evaluates the container and the index of target, an
+// index expression or hash.key, which is short for hash["key"]. An error
+// is given back as the container.
+func evalElementTarget(target ast.Expression, env *object.Environment) (object.Object, object.Object) {
+	if member, ok := target.(*ast.MemberExpression); ok {
+		receiver := Eval(member.Object, env)
+		if isAbrupt(receiver) {
+			return receiver, nil
+		}
+		if receiver.Type() != object.HASH_OBJ {
+			return newError("cannot assign to field %s of %s", member.Property.Value, receiver.Type()), nil
+		}
+		return receiver, &object.String{Value: member.Property.Value}
+	}
+
+	index := target.(*ast.IndexExpression)
+	left := Eval(index.Left, env)
+	if isAbrupt(left) {
+		return left, nil
+	}
+	return left, Eval(index.Index, env)
+}
+
+// evalUpdateExpression evaluates ++ and --. The prefix form gives the new
+// value and the postfix form the one it replaced.
+func evalUpdateExpression(node *ast.UpdateExpression, env *object.Environment) object.Object {
+	operator := node.Operator[:1]
+	old, val := evalUpdate(node.Target, env, func(old object.Object) object.Object {
+		return evalInfixExpression(old, operator, &object.Integer{Value: 1})
+	})
+
+	if isAbrupt(val) || node.Prefix {
+		return val
+	}
+	return old
+}
+
+// evalUpdate replaces the value stored at target, a name or an element,
+// with update(old). It returns both values, or the same error twice.
+func evalUpdate(target ast.Expression, env *object.Environment, update func(object.Object) object.Object) (object.Object, object.Object) {
+	switch target := target.(type) {
+	case *ast.Identifier:
+		old, ok := env.Get(target.Value)
+		if !ok {
+			err := newError("assignment to undeclared variable: %s", target.Value)
+			return err, err
+		}
+
+		val := update(old)
+		if isAbrupt(val) {
+			return val, val
+		}
+		env.Assign(target.Value, val)
+		return old, val
+	case *ast.IndexExpression, *ast.MemberExpression:
+		left, index := evalElementTarget(target, env)
+		if isAbrupt(left) {
+			return left, left
+		}
+		if isAbrupt(index) {
+			return index, index
+		}
+
+		old := evalElement(left, index)
+		if isAbrupt(old) {
+			return old, old
+		}
+
+		val := update(old)
+		if isAbrupt(val) {
+			return val, val
+		}
+		if err := setIndex(left, index, val); err != nil {
+			return err, err
+		}
+		return old, val
+	}
+
+	err := newError("cannot assign to %s", target)
+	return err, err
+}
+
+// evalElement is like evalIndexExpression, but a missing element is an
+// error instead of NULL since there is nothing to update.
+func evalElement(left, index object.Object) object.Object {
+	switch {
+	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
+		elements := left.(*object.Array).Elements
+		n := index.(*object.Integer).Value
+		idx, ok := elementIndex(n, int64(len(elements)))
+		if !ok {
+			return newError("index out of range: %d (length %d)", n, len(elements))
+		}
+		return elements[idx]
+	case left.Type() == object.HASH_OBJ:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		pair, ok := left.(*object.Hash).Pairs[key.HashKey()]
+		if !ok {
+			return newError("key not found: %s", index.Inspect())
+		}
+		return pair.Value
+	default:
+		return newError("index operator not supported: %s", left.Type())
+	}
+}
+
+// setIndex stores val in the array or hash left, changing it in place. A
+// hash gets a new pair for a missing key, an array index must exist.
+func setIndex(left, index, val object.Object) *object.Error {
+	switch left := left.(type) {
+	case *object.Array:
+		integer, ok := index.(*object.Integer)
+		if !ok {
+			return newError("array index must be INTEGER, got %s", index.Type())
+		}
+		idx, ok := elementIndex(integer.Value, int64(len(left.Elements)))
+		if !ok {
+			return newError("index out of range: %d (length %d)", integer.Value, len(left.Elements))
+		}
+		left.Elements[idx] = val
+	case *object.Hash:
+		key, ok := index.(object.Hashable)
+		if !ok {
+			return newError("unusable as hash key: %s", index.Type())
+		}
+		left.Pairs[key.HashKey()] = object.HashPair{Key: index, Value: val}
+	default:
+		return newError("index assignment not supported: %s", left.Type())
+	}
+	return nil
+}
+
 func applyFunction(fn object.Object, args []object.Object) object.Object {
 
 	switch fn := fn.(type) {
 	case *object.Function:
-		extendedEnv := extendFunctionEnv(fn, args)
+		extendedEnv, err := extendFunctionEnv(fn, args)
+		if err != nil {
+			return err
+		}
 		evaluated := Eval(fn.Body, extendedEnv)
 		return unwrapReturnValue(evaluated)
 	case *object.Builtin:
 		return fn.Fn(args...)
+	case *object.Method:
+		return callMethod(fn, args)
 	default:
 		return newError("not a function: %s", fn.Type())
 	}
 }
 
-func extendFunctionEnv(fn *object.Function, args []object.Object) *object.Environment {
+// extendFunctionEnv binds the parameters of fn to args. Missing optional
+// parameters get their default, which may refer to the parameters before
+// it, and ...rest gets an array of whatever args are left.
+func extendFunctionEnv(fn *object.Function, args []object.Object) (*object.Environment, *object.Error) {
+	required := len(fn.Parameters) - len(fn.Defaults)
+	if len(args) < required || fn.Rest == nil && len(args) > len(fn.Parameters) {
+		return nil, arityError(fn, len(args))
+	}
+
 	env := object.NewEnclosedEnvironment(fn.Env)
 
 	for paramIdx, param := range fn.Parameters {
-		env.Set(param.Value, args[paramIdx])
+		if paramIdx < len(args) {
+			env.Set(param.Value, args[paramIdx])
+			continue
+		}
+
+		val := Eval(fn.Defaults[param.Value], env)
+		if err, ok := val.(*object.Error); ok {
+			return nil, err
+		}
+		env.Set(param.Value, val)
 	}
-	return env
+
+	if fn.Rest != nil {
+		rest := []object.Object{}
+		if len(args) > len(fn.Parameters) {
+			rest = append(rest, args[len(fn.Parameters):]...)
+		}
+		env.Set(fn.Rest.Value, &object.Array{Elements: rest})
+	}
+	return env, nil
+}
+
+func arityError(fn *object.Function, got int) *object.Error {
+	name := fn.Name
+	if name == "" {
+		name = "anonymous kriya"
+	}
+
+	required := len(fn.Parameters) - len(fn.Defaults)
+	want := fmt.Sprintf("%d", required)
+	switch {
+	case fn.Rest != nil:
+		want = fmt.Sprintf("at least %d", required)
+	case required < len(fn.Parameters):
+		want = fmt.Sprintf("%d to %d", required, len(fn.Parameters))
+	}
+
+	return newError("wrong number of arguments to %s: want=%s, got=%d", name, want, got)
 }
 
 func unwrapReturnValue(obj object.Object) object.Object {
@@ -334,29 +756,144 @@ func evalStringInfixExpression(operator string, left object.Object, right object
 	return &object.String{Value: leftVal + rightVal}
 }
 
+func evalInterpolatedString(node *ast.InterpolatedString, env *object.Environment) object.Object {
+	var out bytes.Buffer
+
+	for _, part := range node.Parts {
+		value := Eval(part, env)
+		if isAbrupt(value) {
+			return value
+		}
+		out.WriteString(value.Inspect())
+	}
+
+	return &object.String{Value: out.String()}
+}
+
 func evalIndexExpression(left, index object.Object) object.Object {
 	switch {
 	case left.Type() == object.ARRAY_OBJ && index.Type() == object.INTEGER_OBJ:
 		return evalArrayIndexExpression(left, index)
+	case left.Type() == object.STRING_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalStringIndexExpression(left, index)
 	case left.Type() == object.HASH_OBJ:
 		return evalHashIndexExpression(left, index)
+	case left.Type() == object.RANGE_OBJ && index.Type() == object.INTEGER_OBJ:
+		return evalRangeIndexExpression(left, index)
 	default:
 		return newError("index operator not supported: %s", left.Type())
 	}
 }
 
+func evalRangeIndexExpression(rangeObj, index object.Object) object.Object {
+	r := rangeObj.(*object.Range)
+
+	idx, ok := elementIndex(index.(*object.Integer).Value, r.Len())
+	if !ok {
+		return NULL
+	}
+	return &object.Integer{Value: r.At(idx)}
+}
+
 func evalArrayIndexExpression(array, index object.Object) object.Object {
 	arrayObject := array.(*object.Array)
 
-	idx := index.(*object.Integer).Value
+	idx, ok := elementIndex(index.(*object.Integer).Value, int64(len(arrayObject.Elements)))
+	if !ok {
+		return NULL
+	}
+
+	return arrayObject.Elements[idx]
+}
+
+// elementIndex turns idx into an index from the start of a sequence of
+// the given length, a negative idx counting from the end so -1 is the last
+// element. ok is false when there is no element at idx.
+func elementIndex(idx, length int64) (int64, bool) {
+	if idx < 0 {
+		idx += length
+	}
+	return idx, idx >= 0 && idx < length
+}
 
-	max := int64(len(arrayObject.Elements) - 1)
+// evalStringIndexExpression gives the rune at index as a string
+func evalStringIndexExpression(str, index object.Object) object.Object {
+	runes := []rune(str.(*object.String).Value)
 
-	if idx < 0 || idx > max {
+	idx, ok := elementIndex(index.(*object.Integer).Value, int64(len(runes)))
+	if !ok {
 		return NULL
 	}
 
-	return arrayObject.Elements[idx]
+	return &object.String{Value: string(runes[idx])}
+}
+
+// evalSliceExpression evaluates xs[low:high] on arrays and on strings, by
+// rune. Bounds count from the end when negative and are clamped to the
+// length the way Python does, so slicing never fails on a bound.
+func evalSliceExpression(node *ast.SliceExpression, env *object.Environment) object.Object {
+	left := Eval(node.Left, env)
+	if isAbrupt(left) {
+		return left
+	}
+
+	bounds := []object.Object{}
+	for _, exp := range []ast.Expression{node.Low, node.High} {
+		if exp == nil {
+			bounds = append(bounds, NULL)
+			continue
+		}
+		bound := Eval(exp, env)
+		if isAbrupt(bound) {
+			return bound
+		}
+		if bound.Type() != object.INTEGER_OBJ {
+			return newError("slice index must be INTEGER, got %s", bound.Type())
+		}
+		bounds = append(bounds, bound)
+	}
+
+	switch left := left.(type) {
+	case *object.Array:
+		low, high := sliceBounds(bounds[0], bounds[1], len(left.Elements))
+		elements := make([]object.Object, high-low)
+		copy(elements, left.Elements[low:high])
+		return &object.Array{Elements: elements}
+	case *object.String:
+		runes := []rune(left.Value)
+		low, high := sliceBounds(bounds[0], bounds[1], len(runes))
+		return &object.String{Value: string(runes[low:high])}
+	default:
+		return newError("slice operator not supported: %s", left.Type())
+	}
+}
+
+// sliceBounds works out the part of a sequence of the given length that
+// low and high select. Either may be NULL, meaning the start or the end.
+func sliceBounds(low, high object.Object, length int) (int, int) {
+	clamp := func(bound object.Object, fallback int) int {
+		n, ok := bound.(*object.Integer)
+		if !ok {
+			return fallback
+		}
+		idx := n.Value
+		if idx < 0 {
+			idx += int64(length)
+		}
+		if idx < 0 {
+			return 0
+		}
+		if idx > int64(length) {
+			return length
+		}
+		return int(idx)
+	}
+
+	lo, hi := clamp(low, 0), clamp(high, length)
+	if hi < lo {
+		hi = lo
+	}
+	return lo, hi
 }
 
 func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Object {
@@ -365,7 +902,7 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 	for keyNode, valueNode := range node.Pairs {
 		key := Eval(keyNode, env)
 
-		if isError(key) {
+		if isAbrupt(key) {
 			return key
 		}
 
@@ -377,7 +914,7 @@ func evalHashLiteral(node *ast.HashLiteral, env *object.Environment) object.Obje
 
 		value := Eval(valueNode, env)
 
-		if isError(value) {
+		if isAbrupt(value) {
 			return value
 		}
 
@@ -404,13 +941,112 @@ func evalHashIndexExpression(hash, index object.Object) object.Object {
 func evalChakraExpression(w *ast.ChakraStatement, env *object.Environment) object.Object {
 	condition := Eval(w.Condition, env)
 
-	if isError(condition) {
+	if isAbrupt(condition) {
 		return condition
 	}
 
 	for isTruthy(condition) {
-		Eval(w.Body, env)
+		result := Eval(w.Body, env)
+		if result != nil {
+			rt := result.Type()
+			if rt == object.BREAK_OBJ {
+				break
+			}
+			if rt == object.RETURN_VALUE_OBJ || rt == object.ERROR_OBJ {
+				return result
+			}
+		}
+
 		condition = Eval(w.Condition, env)
+		if isAbrupt(condition) {
+			return condition
+		}
 	}
 	return NULL
 }
+
+// evalPratyekStatement runs the body once per item of the iterable, each
+// time in a fresh scope holding the loop variables
+func evalPratyekStatement(node *ast.PratyekStatement, env *object.Environment) object.Object {
+	iterable := Eval(node.Iterable, env)
+	if isAbrupt(iterable) {
+		return iterable
+	}
+
+	if len(node.Variables) == 2 && iterable.Type() == object.SET_OBJ {
+		return newError("cannot bind a key and a value iterating over %s", iterable.Type())
+	}
+
+	var result object.Object = NULL
+	err := iterate(iterable, func(key, value object.Object) bool {
+		loopEnv := object.NewEnclosedEnvironment(env)
+		if len(node.Variables) == 2 {
+			loopEnv.Set(node.Variables[0].Value, key)
+			loopEnv.Set(node.Variables[1].Value, value)
+		} else if iterable.Type() == object.HASH_OBJ {
+			loopEnv.Set(node.Variables[0].Value, key)
+		} else {
+			loopEnv.Set(node.Variables[0].Value, value)
+		}
+
+		r := Eval(node.Body, loopEnv)
+		if r != nil {
+			switch r.Type() {
+			case object.BREAK_OBJ:
+				return false
+			case object.RETURN_VALUE_OBJ, object.ERROR_OBJ:
+				result = r
+				return false
+			}
+		}
+		return true
+	})
+
+	if err != nil {
+		return err
+	}
+	return result
+}
+
+// iterate calls fn with the key and value of each item of collection until
+// fn returns false. Array and string keys are indexes, a string is walked
+// by rune and a set gives each element as both key and value.
+func iterate(collection object.Object, fn func(key, value object.Object) bool) *object.Error {
+	switch collection := collection.(type) {
+	case *object.Array:
+		for i, element := range collection.Elements {
+			if !fn(&object.Integer{Value: int64(i)}, element) {
+				break
+			}
+		}
+	case *object.String:
+		i := 0
+		for _, r := range collection.Value {
+			if !fn(&object.Integer{Value: int64(i)}, &object.String{Value: string(r)}) {
+				break
+			}
+			i++
+		}
+	case *object.Hash:
+		for _, pair := range collection.SortedPairs() {
+			if !fn(pair.Key, pair.Value) {
+				break
+			}
+		}
+	case *object.Set:
+		for _, element := range collection.SortedElements() {
+			if !fn(element, element) {
+				break
+			}
+		}
+	case *object.Range:
+		for i := int64(0); i < collection.Len(); i++ {
+			if !fn(&object.Integer{Value: i}, &object.Integer{Value: collection.At(i)}) {
+				break
+			}
+		}
+	default:
+		return newError("cannot iterate over %s", collection.Type())
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package eval
 
 import (
+	"math"
 	"testing"
 
 	"github.com/psidh/Ganges/src/lexer"
@@ -28,6 +29,9 @@ func TestEvalIntegerExpression(t *testing.T) {
 		{"3 * 3 * 3 + 10", 37},
 		{"3 * (3 * 3) + 10", 37},
 		{"(5 + 10 * 2 + 15 / 3) * 2 + -10", 50},
+		{"10 % 3", 1},
+		{"-7 % 3", -1},
+		{"2 + 9 % 4 * 3", 5},
 	}
 
 	for _, test := range tests {
@@ -36,6 +40,59 @@ func TestEvalIntegerExpression(t *testing.T) {
 	}
 }
 
+func TestEvalFloatExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14", 3.14},
+		{"-2.5", -2.5},
+		{"7.0 / 2", 3.5},
+		{"7 / 2.0", 3.5},
+		{"0.1 + 0.2 * 10", 2.1},
+		{"1 + 0.5", 1.5},
+		{"2.5 - 3", -0.5},
+		{"1e3 * 2", 2000},
+		{"7.5 % 2", 1.5},
+	}
+
+	for _, tt := range tests {
+		testFloatObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFloatComparisons(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1.5 < 2", true},
+		{"2 > 1.5", true},
+		{"2.0 == 2", true},
+		{"2 != 2.0", false},
+		{"0.1 + 0.2 == 0.3", false},
+	}
+
+	for _, tt := range tests {
+		testBooleanObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func testFloatObject(t *testing.T, obj object.Object, expected float64) bool {
+	result, ok := obj.(*object.Float)
+
+	if !ok {
+		t.Errorf("object is not Float. got=%T, (%+v)", obj, obj)
+		return false
+	}
+
+	if math.Abs(result.Value-expected) > 1e-9 {
+		t.Errorf("object has incorrect value. got=%g, want=%g", result.Value, expected)
+		return false
+	}
+	return true
+}
+
 func testEval(input string) object.Object {
 	l := lexer.New(input)
 	p := parser.New(l)
@@ -84,6 +141,21 @@ func TestEvalBooleanExpression(t *testing.T) {
 		{"(1 < 2) == asatya", false},
 		{"(1 > 2) == satya", false},
 		{"(1 > 2) == asatya", true},
+		{"1 <= 2", true},
+		{"2 <= 2", true},
+		{"3 <= 2", false},
+		{"1 >= 2", false},
+		{"2 >= 2", true},
+		{"1.5 >= 1", true},
+		{"satya && satya", true},
+		{"satya && asatya", false},
+		{"asatya || satya", true},
+		{"asatya || asatya", false},
+		{"1 && 0", true},
+		{"1 < 2 && 2 < 3", true},
+		{"asatya && foobar", false},
+		{"satya || foobar", true},
+		{"rama x = 0; chakra (x < 10 && x % 4 != 3) { rama x = x + 1; } x == 3", true},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -138,6 +210,11 @@ func TestIfElseExpressions(t *testing.T) {
 		{"yadi (1 > 2) { 10 }", nil},
 		{"yadi (1 > 2) { 10 } anyatha { 20 }", 20},
 		{"yadi (1 < 2) { 10 } anyatha { 20 }", 10},
+		{"yadi (1 > 2) { 10 } anyatha yadi (2 > 1) { 20 } anyatha { 30 }", 20},
+		{"yadi (1 > 2) { 10 } anyatha yadi (2 > 3) { 20 } anyatha { 30 }", 30},
+		{"yadi (1 > 2) { 10 } anyatha yadi (2 > 3) { 20 }", nil},
+		{"yadi (1 < 2) { 10 } anyatha yadi (x) { 20 }", 10},
+		{"yadi (1 > 2) { 10 } anyatha yadi (2 > 3) { 20 } anyatha yadi (satya) { 40 }", 40},
 	}
 
 	for _, test := range tests {
@@ -160,6 +237,35 @@ func testNullObject(t *testing.T, obj object.Object) bool {
 	return true
 }
 
+func testStringObject(t *testing.T, obj object.Object, expected string) bool {
+	result, ok := obj.(*object.String)
+	if !ok {
+		t.Errorf("object is not String. got=%T, (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has incorrect value. got=%q, want=%q", result.Value, expected)
+		return false
+	}
+	return true
+}
+
+// testErrorMessage checks that evaluating input fails with the expected error
+func testErrorMessage(t *testing.T, input string, expected string) bool {
+	evaluated := testEval(input)
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Errorf("%q: no error object returned. got=%T (%+v)", input, evaluated, evaluated)
+		return false
+	}
+	if errObj.Message != expected {
+		t.Errorf("%q: wrong error message. expected=%q, got=%q", input, expected, errObj.Message)
+		return false
+	}
+	return true
+}
+
 func TestReturnStatements(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -169,6 +275,7 @@ func TestReturnStatements(t *testing.T) {
 		{"daan 10; 9;", 10},
 		{"daan 2 * 5; 9;", 10},
 		{"9; daan 2 * 5; 9;", 10},
+		{"rama f = kriya(x) { yadi (x) { daan 1 } 5 }; f(asatya);", 5},
 	}
 	for _, tt := range tests {
 		evaluated := testEval(tt.input)
@@ -218,6 +325,26 @@ func TestErrorHandling(t *testing.T) {
 			`"Hello" - "World"`,
 			"unknown operator: STRING - STRING",
 		},
+		{
+			"-satya + 1.5",
+			"unknown operator: -BOOLEAN",
+		},
+		{
+			"10 / 0",
+			"division by zero: 10 / 0",
+		},
+		{
+			"10 % (5 - 5)",
+			"division by zero: 10 % 0",
+		},
+		{
+			"satya && foobar",
+			"identifier not found: foobar",
+		},
+		{
+			"1.5 + satya",
+			"type mismatch: FLOAT + BOOLEAN",
+		},
 		{
 			`{"name": "Monkey"}[kriya(x) { x }];`,
 			"unusable as hash key: FUNCTION",
@@ -348,6 +475,7 @@ func TestBuiltinFunctions(t *testing.T) {
 		{`dairghya("")`, 0},
 		{`dairghya("four")`, 4},
 		{`dairghya("hello world")`, 11},
+		{`dairghya("गंगा")`, 4},
 		{`dairghya(1)`, "argument to `dairghya` not supported, got INTEGER"},
 		{`dairghya("one", "two")`, "wrong number of arguments. got=2, want=1"},
 	}
@@ -437,6 +565,14 @@ func TestArrayIndexExpressions(t *testing.T) {
 		},
 		{
 			"[1, 2, 3][-1]",
+			3,
+		},
+		{
+			"[1, 2, 3][-3]",
+			1,
+		},
+		{
+			"[1, 2, 3][-4]",
 			nil,
 		},
 	}
@@ -541,6 +677,7 @@ func TestChakraLoop(t *testing.T) {
 		expected int64
 	}{
 		{"rama x = 0; chakra(x < 10){ rama x = x + 1;} x;", 10},
+		{"rama f = kriya() { rama x = 0; chakra (satya) { x = x + 1; yadi (x == 3) { daan x; } } }; f();", 3},
 	}
 
 	for _, test := range tests {
@@ -548,3 +685,669 @@ func TestChakraLoop(t *testing.T) {
 		testIntegerObject(t, evaluated, test.expected)
 	}
 }
+
+func TestErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5 + satya;", "ERROR: main.ga:1:1: type mismatch: INTEGER + BOOLEAN"},
+		{"rama x = 1;\n  x + foobar;", "ERROR: main.ga:2:7: identifier not found: foobar"},
+		{"rama f = kriya() {\n  -satya\n};\nf();", "ERROR: main.ga:2:3: unknown operator: -BOOLEAN"},
+		{`dairghya(1)`, "ERROR: main.ga:1:1: argument to `dairghya` not supported, got INTEGER"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewFile("main.ga", tt.input)
+		p := parser.New(l)
+		program := p.ParseProgram()
+		evaluated := Eval(program, object.NewEnvironment())
+
+		errObj, ok := evaluated.(*object.Error)
+		if !ok {
+			t.Errorf("no error object returned. got=%T (%+v)", evaluated, evaluated)
+			continue
+		}
+
+		if errObj.Inspect() != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errObj.Inspect())
+		}
+	}
+}
+
+func TestStringEscapesAndRawStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"line\none"`, "line\none"},
+		{`"quote: \"" + "\\"`, `quote: "\`},
+		{"`{\"port\": 8080,\n \"debug\": satya}`", "{\"port\": 8080,\n \"debug\": satya}"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDevanagariProgram(t *testing.T) {
+	input := `
+राम जोड़ = क्रिया(क, ख) { दान क + ख; };
+राम परिणाम = जोड़(2, 3);
+यदि (परिणाम > 4) { परिणाम } अन्यथा { 0 }
+`
+	testIntegerObject(t, testEval(input), 5)
+}
+
+func TestIntegerLiteralBases(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xff + 1", 256},
+		{"0b1010 * 2", 20},
+		{"0o755 % 8", 5},
+		{"1_000_000 / 1_000", 1000},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestInterpolatedStrings(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`rama name = "Sita"; "Namaste, ${name}!"`, "Namaste, Sita!"},
+		{`rama items = [1, 2, 3]; "you have ${dairghya(items)} items"`, "you have 3 items"},
+		{`"${1 + 1} ${2.5} ${satya} ${[1, "a"]}"`, "2 2.5 true [1, a]"},
+		{`rama x = 5; "outer ${"inner ${x * 2}"}"`, "outer inner 10"},
+		{`"cost: \${price}"`, "cost: ${price}"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testErrorMessage(t, `"value: ${missing}"`, "identifier not found: missing")
+}
+
+func TestAssignExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"rama i = 0; chakra (i < 5) { i = i + 1; }; i;", 5},
+		{"rama a = 1; a = 2;", 2},
+		{"rama a = 1; rama b = 1; a = b = 7; a + b;", 14},
+		{`rama counter = kriya() {
+			rama count = 0;
+			kriya() { count = count + 1; count };
+		};
+		rama next = counter();
+		next(); next();
+		next();`, 3},
+		{`rama total = 0;
+		rama add = kriya(x) { total = total + x; };
+		add(2); add(3);
+		total;`, 5},
+		{`rama x = 1;
+		rama shadow = kriya() { rama x = 10; x = x + 1; x };
+		shadow() + x;`, 12},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestAssignUndeclared(t *testing.T) {
+	evaluated := testEval("rama f = kriya() { y = 1; }; f();")
+
+	errObj, ok := evaluated.(*object.Error)
+	if !ok {
+		t.Fatalf("no error object returned. got=%T (%+v)", evaluated, evaluated)
+	}
+	if errObj.Message != "assignment to undeclared variable: y" {
+		t.Errorf("wrong error message. got=%q", errObj.Message)
+	}
+	if errObj.Pos.Column != 20 {
+		t.Errorf("wrong error position. got=%s", errObj.Pos)
+	}
+}
+
+func TestChakraLoopStopsOnError(t *testing.T) {
+	testErrorMessage(t, "rama i = 0; chakra (i < 5) { j = i + 1; }", "assignment to undeclared variable: j")
+}
+
+func TestCompoundAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"rama a = 5; a += 3; a;", 8},
+		{"rama a = 5; a -= 3;", 2},
+		{"rama a = 5; a *= 3; a;", 15},
+		{"rama a = 7; a /= 2; a;", 3},
+		{"rama a = 7; a %= 4; a;", 3},
+		{"rama a = 1; a += 0.5; a;", 1.5},
+		{"rama i = 0; rama s = 0; chakra (i < 4) { s += i; i++; }; s;", 6},
+		{"rama i = 5; i++;", 5},
+		{"rama i = 5; i++; i;", 6},
+		{"rama i = 5; ++i;", 6},
+		{"rama i = 5; i--; i;", 4},
+		{"rama i = 5; --i;", 4},
+		{"rama xs = [1, 2, 3]; xs[1] += 10; xs[1];", 12},
+		{"rama xs = [1, 2, 3]; xs[2]++; xs;", []int64{1, 2, 4}},
+		{`rama h = {"n": 1}; h["n"] *= 6; h["n"];`, 6},
+		{`rama h = {"n": 1}; --h["n"];`, 0},
+		{"rama n = 0; rama inc = kriya() { n++; }; inc(); inc(); n;", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case float64:
+			testFloatObject(t, evaluated, expected)
+		case []int64:
+			array, ok := evaluated.(*object.Array)
+			if !ok {
+				t.Errorf("object is not Array. got=%T (%+v)", evaluated, evaluated)
+				continue
+			}
+			if len(array.Elements) != len(expected) {
+				t.Errorf("wrong num of elements. want=%d, got=%d", len(expected), len(array.Elements))
+				continue
+			}
+			for i, want := range expected {
+				testIntegerObject(t, array.Elements[i], want)
+			}
+		}
+	}
+}
+
+func TestCompoundAssignmentErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"x += 1;", "assignment to undeclared variable: x"},
+		{"y++;", "assignment to undeclared variable: y"},
+		{`rama s = 1; s += "a";`, "type mismatch: INTEGER + STRING"},
+		{`rama s = "a"; s -= "b";`, "unknown operator: STRING - STRING"},
+		{`rama b = satya; b++;`, "type mismatch: BOOLEAN + INTEGER"},
+		{"rama a = 1; a /= 0;", "division by zero: 1 / 0"},
+		{"rama xs = [1]; xs[3] += 1;", "index out of range: 3 (length 1)"},
+		{`rama h = {}; h["x"]++;`, `key not found: x`},
+		{`rama s = "abc"; s[0] += "d";`, "index operator not supported: STRING"},
+	}
+
+	for _, tt := range tests {
+		testErrorMessage(t, tt.input, tt.expectedMessage)
+	}
+}
+
+func TestIndexAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"rama xs = [1, 2, 3]; xs[0] = 9; xs[0];", 9},
+		{"rama xs = [1, 2, 3]; xs[2] = 7;", 7},
+		{"rama xs = [1, 2, 3]; rama i = 1; xs[i + 1] = xs[i] * 10; xs[2];", 20},
+		{"rama xs = [1, 2]; rama ys = xs; ys[0] = 5; xs[0];", 5},
+		{"rama grid = [[0, 0], [0, 0]]; grid[1][0] = 4; grid[1][0];", 4},
+		{`rama config = {"port": 80}; config["port"] = 8080; config["port"];`, 8080},
+		{`rama config = {}; config["port"] = 8080; config["port"];`, 8080},
+		{`rama h = {}; h[1] = 1; h[satya] = 2; h[1] + h[satya];`, 3},
+		{`rama h = {"a": 1}; h["b"] = 2; h["a"] + h["b"];`, 3},
+		{"rama h = {}; rama set = kriya(k, v) { h[k] = v; }; set(2, 3); h[2];", 3},
+		{"rama xs = [1, 2, 3]; xs[-1] = 9; xs[2];", 9},
+		{"rama xs = [1, 2, 3]; xs[-3] += 10; xs[0];", 11},
+		{"rama xs = [1, 2, 3]; xs[-2]++; xs[1];", 3},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestIndexAssignmentErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"rama xs = [1, 2]; xs[2] = 0;", "index out of range: 2 (length 2)"},
+		{"rama xs = [1, 2]; xs[-3] = 0;", "index out of range: -3 (length 2)"},
+		{"rama xs = [1, 2]; xs[-3] += 1;", "index out of range: -3 (length 2)"},
+		{`rama xs = [1, 2]; xs["a"] = 0;`, "array index must be INTEGER, got STRING"},
+		{`rama h = {}; h[kriya(x) { x }] = 1;`, "unusable as hash key: FUNCTION"},
+		{`rama s = "abc"; s[0] = "x";`, "index assignment not supported: STRING"},
+		{"ys[0] = 1;", "identifier not found: ys"},
+		{"rama xs = [1]; xs[0] = zz;", "identifier not found: zz"},
+	}
+
+	for _, tt := range tests {
+		testErrorMessage(t, tt.input, tt.expectedMessage)
+	}
+}
+
+func TestBreakAndContinue(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"rama i = 0; chakra (satya) { i++; yadi (i == 3) { viram; } }; i;", 3},
+		{"rama i = 0; rama s = 0; chakra (i < 5) { i++; yadi (i % 2 == 0) { agla; } s += i; }; s;", 9},
+		{`rama xs = [4, 8, 15, 16];
+		rama found = -1;
+		rama i = 0;
+		chakra (i < 4) {
+			yadi (xs[i] > 10) { found = i; viram; }
+			i++;
+		};
+		found;`, 2},
+		{`rama n = 0;
+		rama i = 0;
+		chakra (i < 3) {
+			i++;
+			rama j = 0;
+			chakra (satya) { j++; yadi (j > i) { viram; } n++; }
+		};
+		n;`, 6},
+		{"rama f = kriya() { chakra (satya) { daan 7; viram; } }; f();", 7},
+		{"rama i = 0; chakra (i < 3) { i++; agla; i = 100; }; i;", 3},
+		{"rama i = 0; chakra (i < 5) { i++; rama y = yadi (i == 2) { viram; }; }; i;", 2},
+		{"rama s = 0; pratyek (x : 1..5) { s += milana (x) { 3 => yadi (satya) { agla; }, _ => x }; }; s;", 12},
+		{"rama s = 0; pratyek (x : 1..5) { s = s + milana (x) { 3 => yadi (satya) { agla; }, _ => x }; }; s;", 12},
+		{"rama xs = []; pratyek (x : 1..5) { xs = push(xs, yadi (x > 2) { viram; } anyatha { x }); }; dairghya(xs);", 2},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestPratyekStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"rama s = 0; pratyek (x : [1, 2, 3]) { s += x; }; s;", 6},
+		{"rama s = 0; pratyek (i, x : [5, 6, 7]) { s += i * x; }; s;", 20},
+		{`rama s = ""; pratyek (k : {"b": 2, "a": 1, "c": 3}) { s += k; }; s;`, "abc"},
+		{`rama s = ""; pratyek (k, v : {"b": 2, "a": 1}) { s += "${k}=${v};"; }; s;`, "a=1;b=2;"},
+		{"rama s = 0; pratyek (x : set(1, 2, 3, 2)) { s += x; }; s;", 6},
+		{`rama s = ""; pratyek (c : "नमस्ते") { s = c + s; }; s;`, "ेत्समन"},
+		{`rama n = 0; pratyek (i, c : "héllo") { n = i; }; n;`, 4},
+		{"rama s = 0; pratyek (x : [1, 2, 3, 4]) { yadi (x == 3) { viram; } s += x; }; s;", 3},
+		{"rama s = 0; pratyek (x : [1, 2, 3, 4]) { yadi (x % 2 == 0) { agla; } s += x; }; s;", 4},
+		{"rama find = kriya(xs) { pratyek (x : xs) { yadi (x > 1) { daan x; } } }; find([1, 5, 9]);", 5},
+		{"pratyek (x : []) { x; }", nil},
+		{"rama x = 1; pratyek (x : [7, 8]) { x; }; x;", 1},
+		{`rama fs = []; pratyek (x : [1, 2]) { fs = push(fs, kriya() { x }); }; fs[0]() + fs[1]() * 10;`, 21},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestPratyekErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"pratyek (x : 5) { x; }", "cannot iterate over INTEGER"},
+		{"pratyek (k, v : set(1)) { k; }", "cannot bind a key and a value iterating over SET"},
+		{"pratyek (x : [1, 2]) { y; }", "identifier not found: y"},
+		{"pratyek (x : ys) { x; }", "identifier not found: ys"},
+	}
+
+	for _, tt := range tests {
+		testErrorMessage(t, tt.input, tt.expectedMessage)
+	}
+}
+
+func TestRanges(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"rama s = 0; pratyek (i : 1..10) { s += i; }; s;", 55},
+		{"rama s = 0; pratyek (i : 0..<10) { s += i; }; s;", 45},
+		{"rama s = 0; pratyek (i : 0..<10 kadam 3) { s += i; }; s;", 18},
+		{"rama s = 0; pratyek (i : 10..1 kadam -4) { s = s * 10 + i; }; s;", 1062},
+		{"rama s = 0; pratyek (i, x : 5..7) { s += i * x; }; s;", 20},
+		{"rama s = 0; pratyek (i : 1..1000000) { yadi (i > 3) { viram; } s += i; }; s;", 6},
+		{"rama n = 4; dairghya(0..<n * 2);", 8},
+		{"dairghya(5..1);", 0},
+		{"dairghya(0..9223372036854775806 kadam 2);", 4611686018427387904},
+		{"(1..10 kadam 2)[3];", 7},
+		{"(0..<5)[5];", nil},
+		{"(0..<5)[-1];", 4},
+		{"(1..10 kadam 2)[-2];", 7},
+		{"(0..<5)[-6];", nil},
+		{"has(1..10, 10);", true},
+		{"has(1..<10, 10);", false},
+		{"has(0..20 kadam 5, 15);", true},
+		{"has(0..20 kadam 5, 16);", false},
+		{`has(0..20, "a");`, false},
+		{"0..<3;", "0..<3"},
+		{"1..9 kadam 4;", "1..9 kadam 4"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case bool:
+			testBooleanObject(t, evaluated, expected)
+		case string:
+			if evaluated.Inspect() != expected {
+				t.Errorf("%q: wrong Inspect. want=%q, got=%q", tt.input, expected, evaluated.Inspect())
+			}
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestRangeErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"1..2.5;", "range bounds must be INTEGER, got FLOAT"},
+		{`"a"..<5;`, "range bounds must be INTEGER, got STRING"},
+		{"0..10 kadam 0;", "range step must not be 0"},
+		{"0..n;", "identifier not found: n"},
+		{"rama r = 0..3; r[1] = 5;", "index assignment not supported: RANGE"},
+	}
+
+	for _, tt := range tests {
+		testErrorMessage(t, tt.input, tt.expectedMessage)
+	}
+}
+
+func TestBareReturn(t *testing.T) {
+	testNullObject(t, testEval("rama f = kriya() { daan; 5 }; f();"))
+	testNullObject(t, testEval("rama f = kriya(x) { yadi (x) { daan } 5 }; f(satya);"))
+	testNullObject(t, testEval("rama f = kriya() { daan\nrama x = 1; x }; f();"))
+}
+
+func TestMatchExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`milana (5) { x yadi (x > 1) => "big", _ => "small" }`, "big"},
+		{`milana (1) { 1 => "one", 2 => "two" }`, "one"},
+		{`milana (2) { 1 => "one", 2 => "two" }`, "two"},
+		{`milana (3) { 1 => "one", _ => "many" }`, "many"},
+		{`milana (3) { 1 => "one" }`, nil},
+		{`milana ("a") { "a" => 1, "b" => 2 }`, 1},
+		{`milana (2.0) { 2 => "int", _ => "other" }`, "int"},
+		{`milana (-1) { -1 => "minus one", _ => "other" }`, "minus one"},
+		{`milana (asatya) { satya => 1, asatya => 0 }`, 0},
+		{`milana ("1") { 1 => "int", _ => "other" }`, "other"},
+		{`milana (5) { n => n * 2 }`, 10},
+		{`milana ([1, 2]) { [a] => a, [a, b] => a + b }`, 3},
+		{`milana ([1, [2, 3]]) { [1, [x, y]] => x * y }`, 6},
+		{`milana ([1, 2]) { [2, x] => x, [1, x] => x * 10 }`, 20},
+		{`milana ({"k": 4, "j": 1}) { {"k": v} => v }`, 4},
+		{`milana ({"j": 1}) { {"k": v} => v, _ => 0 }`, 0},
+		{`milana ({"type": "circle", "r": 2}) { {"type": "square", "side": s} => s * s, {"type": "circle", "r": r} => 3 * r * r }`, 12},
+		{`milana (7) { n yadi n % 2 == 0 => "even", n yadi n > 5 => "big odd", _ => "odd" }`, "big odd"},
+		{`milana ([3, 1]) { [a, b] yadi a < b => "asc", [a, b] => "desc" }`, "desc"},
+		{`rama n = 1; milana (5) { n => n }; n;`, 1},
+		{`rama describe = kriya(x) { milana (x) { 0 => "zero", n yadi n < 0 => "negative", _ => "positive" } }; describe(-4);`, "negative"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		switch expected := tt.expected.(type) {
+		case int:
+			testIntegerObject(t, evaluated, int64(expected))
+		case string:
+			testStringObject(t, evaluated, expected)
+		default:
+			testNullObject(t, evaluated)
+		}
+	}
+}
+
+func TestMatchExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"milana (y) { _ => 1 }", "identifier not found: y"},
+		{"milana (1) { n yadi z => 1 }", "identifier not found: z"},
+		{"milana (1) { n => n + satya }", "type mismatch: INTEGER + BOOLEAN"},
+	}
+
+	for _, tt := range tests {
+		testErrorMessage(t, tt.input, tt.expectedMessage)
+	}
+}
+
+func TestDestructuringRama(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"rama [x, y] = [3, 4]; x * 10 + y;", 34},
+		{"rama f = kriya() { [1, 2] }; rama [a, b] = f(); a + b;", 3},
+		{`rama person = {"name": "Sita", "age": 30}; rama {"age": a} = person; a;`, 30},
+		{`rama {"p": [x, y], "q": q} = {"p": [1, 2], "q": 3}; x + y + q;`, 6},
+		{"rama [head, ...tail] = [1, 2, 3]; head * 100 + dairghya(tail) * 10 + tail[1];", 123},
+		{"rama [only, ...none] = [7]; only + dairghya(none);", 7},
+		{"rama [_, second] = [1, 2]; second;", 2},
+		{"rama [1, x] = [1, 5]; x;", 5},
+		{"rama xs = [1, 2]; rama [...copy] = xs; copy[0] = 9; xs[0];", 1},
+		{"rama [a, b] = [1, 2]; rama [a, b] = [b, a]; a * 10 + b;", 21},
+		{"rama [first, ...rest] = [1, 2, 3]; milana (rest) { [x, ...more] => x + dairghya(more) };", 3},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	// destructuring gives back null like any other rama
+	testNullObject(t, testEval("rama x = 1;"))
+	testNullObject(t, testEval("rama [x, y] = [1, 2];"))
+}
+
+func TestDestructuringErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"rama [x, y] = [1, 2, 3];", "cannot destructure ARRAY into [x, y]: expected 2 elements, got 3"},
+		{"rama [x, y, ...z] = [1];", "cannot destructure ARRAY into [x, y, ...z]: expected at least 2 elements, got 1"},
+		{"rama [x] = 5;", "cannot destructure INTEGER into [x]: expected ARRAY, got INTEGER"},
+		{`rama {"a": x} = {"b": 1};`, `cannot destructure HASH into {a:x}: key a not found`},
+		{`rama {"a": x} = [1];`, `cannot destructure ARRAY into {a:x}: expected HASH, got ARRAY`},
+		{"rama [[x]] = [5];", "cannot destructure ARRAY into [[x]]: expected ARRAY, got INTEGER"},
+		{"rama [0, x] = [1, 2];", "cannot destructure ARRAY into [0, x]: 1 does not match 0"},
+	}
+
+	for _, tt := range tests {
+		testErrorMessage(t, tt.input, tt.expectedMessage)
+	}
+}
+
+func TestDefaultAndRestParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"rama f = kriya(a, b = 10) { a + b }; f(1);", 11},
+		{"rama f = kriya(a, b = 10) { a + b }; f(1, 2);", 3},
+		{"rama f = kriya(a, b = a * 2) { a + b }; f(3);", 9},
+		{"rama n = 5; rama f = kriya(a = n) { a }; f();", 5},
+		{"rama f = kriya(a, ...rest) { dairghya(rest) }; f(1);", 0},
+		{"rama f = kriya(a, ...rest) { rest[1] }; f(1, 2, 3);", 3},
+		{"rama f = kriya(a, b = 1, ...rest) { a + b + dairghya(rest) }; f(1, 2, 3, 4);", 5},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestArityErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"rama add = kriya(x, y) { x + y }; add(1);", "wrong number of arguments to add: want=2, got=1"},
+		{"rama add = kriya(x, y) { x + y }; add(1, 2, 3);", "wrong number of arguments to add: want=2, got=3"},
+		{"kriya(x) { x }();", "wrong number of arguments to anonymous kriya: want=1, got=0"},
+		{"rama f = kriya(a, b = 1) { a }; f();", "wrong number of arguments to f: want=1 to 2, got=0"},
+		{"rama f = kriya(a, ...rest) { a }; f();", "wrong number of arguments to f: want=at least 1, got=0"},
+		{"rama f = kriya(a = missing) { a }; f();", "identifier not found: missing"},
+	}
+
+	for _, tt := range tests {
+		testErrorMessage(t, tt.input, tt.expectedMessage)
+	}
+}
+
+func TestSliceExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2, 3, 4, 5][1:3]", "[2, 3]"},
+		{"rama n = 2; [1, 2, 3, 4, 5][:n]", "[1, 2]"},
+		{"[1, 2, 3, 4, 5][-2:]", "[4, 5]"},
+		{"[1, 2, 3][:]", "[1, 2, 3]"},
+		{"[1, 2, 3][1:100]", "[2, 3]"},
+		{"[1, 2, 3][-100:1]", "[1]"},
+		{"[1, 2, 3][2:1]", "[]"},
+		{"[1, 2, 3][5:]", "[]"},
+		{"rama xs = [1, 2, 3]; rama ys = xs[:]; ys[0] = 9; xs[0];", "1"},
+		{`"Ganges river"[0:6]`, "Ganges"},
+		{`"गंगा नदी"[-3:]`, "नदी"},
+		{`"abc"[-1]`, "c"},
+		{`"नदी"[1]`, "द"},
+		{`"abc"[3]`, "null"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestSliceErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{`[1, 2, 3]["a":]`, "slice index must be INTEGER, got STRING"},
+		{"[1, 2, 3][:satya]", "slice index must be INTEGER, got BOOLEAN"},
+		{`{"a": 1}[0:1]`, "slice operator not supported: HASH"},
+		{"5[0:1]", "slice operator not supported: INTEGER"},
+		{"xs[0:1]", "identifier not found: xs"},
+	}
+
+	for _, tt := range tests {
+		testErrorMessage(t, tt.input, tt.expectedMessage)
+	}
+}
+
+func TestMemberExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`rama cfg = {"db": {"host": "localhost"}}; cfg.db.host;`, "localhost"},
+		{`rama h = {"double": kriya(x) { x * 2 }}; h.double(4);`, "8"},
+		{"rama arr = [1, 2, 3]; arr.push(4);", "[1, 2, 3, 4]"},
+		{"rama arr = [1, 2, 3]; arr.push(4); arr;", "[1, 2, 3]"},
+		{"[1, 2, 3].len();", "3"},
+		{"[1, 2, 3].pratham() + [1, 2, 3].antha();", "4"},
+		{`"गंगा".len() + "ab".len();`, "6"},
+		{`rama h = {"port": 80}; h.port = 8080; h.port;`, "8080"},
+		{`rama h = {}; h.port = 1; h.port += 2; h["port"];`, "3"},
+		{`rama cfg = {"db": {"hits": 0}}; cfg.db.hits++; cfg.db.hits++; cfg.db.hits;`, "2"},
+		{"rama s = set(1, 2); s.add(3); s.has(3);", "true"},
+		{"rama s = set(1, 2); s.remove(1).has(1);", "false"},
+		{"(1..10).has(10);", "true"},
+		{"(0..<4).len();", "4"},
+		{"rama len = [1, 2].len; len();", "2"},
+		{`{"a": 1}.b;`, "null"},
+		{`rama h = {"a": 1}; h.b == h["b"];`, "true"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if evaluated == nil || evaluated.Inspect() != tt.expected {
+			t.Errorf("%q: expected=%q, got=%v", tt.input, tt.expected, evaluated)
+		}
+	}
+}
+
+func TestMethodErrors(t *testing.T) {
+	tests := []struct {
+		input           string
+		expectedMessage string
+	}{
+		{"[1, 2].pop();", "unknown method pop for ARRAY"},
+		{"5.len();", "unknown method len for INTEGER"},
+		{"[1, 2].push();", "wrong number of arguments to ARRAY.push: want=1, got=0"},
+		{`"abc".len(1);`, "wrong number of arguments to STRING.len: want=0, got=1"},
+		{`{"a": 1}.keys();`, "unknown method keys for HASH"},
+		{`{"a": 1}.b();`, "unknown method b for HASH"},
+		{"[1].foo;", "unknown field foo for ARRAY"},
+		{"[1].foo();", "unknown method foo for ARRAY"},
+		{"rama arr = [1, 2]; arr.len = 5;", "cannot assign to field len of ARRAY"},
+		{`rama s = "ab"; s.len += 1;`, "cannot assign to field len of STRING"},
+		{"rama arr = [1, 2]; arr.len++;", "cannot assign to field len of ARRAY"},
+		{`rama h = {}; h.port += 1;`, "key not found: port"},
+		{"xs.len();", "identifier not found: xs"},
+	}
+
+	for _, tt := range tests {
+		testErrorMessage(t, tt.input, tt.expectedMessage)
+	}
+}
+
+func TestArrowFunctions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"rama double = (x) => x * 2; double(5);", 10},
+		{"rama add = (a, b) => { daan a + b; }; add(2, 3);", 5},
+		{"rama add = (a, b) => { a + b }; add(2, 3);", 5},
+		{"(() => 7)();", 7},
+		{"rama adder = (x) => (y) => x + y; adder(2)(3);", 5},
+		{"rama apply = kriya(f, v) { f(v) }; apply((x) => x * x, 4);", 16},
+		{"rama f = (a, b = 10) => a + b; f(1);", 11},
+	}
+
+	for _, tt := range tests {
+		testIntegerObject(t, testEval(tt.input), tt.expected)
+	}
+
+	testErrorMessage(t, "rama f = (x) => x; f();", "wrong number of arguments to f: want=1, got=0")
+}
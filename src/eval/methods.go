@@ -0,0 +1,107 @@
+package eval
+
+import (
+	"github.com/psidh/Ganges/src/ast"
+	"github.com/psidh/Ganges/src/object"
+)
+
+// method is an entry of a method table. Arity doesn't count the receiver.
+type method struct {
+	arity int
+	fn    func(receiver object.Object, args ...object.Object) object.Object
+}
+
+// methods are the methods of each type, called as value.name(args)
+var methods = map[object.ObjectType]map[string]method{
+	object.ARRAY_OBJ: {
+		"len":     builtinMethod("dairghya", 0),
+		"push":    builtinMethod("push", 1),
+		"pratham": builtinMethod("pratham", 0),
+		"antha":   builtinMethod("antha", 0),
+	},
+	object.STRING_OBJ: {
+		"len": builtinMethod("dairghya", 0),
+	},
+	object.RANGE_OBJ: {
+		"len": builtinMethod("dairghya", 0),
+		"has": builtinMethod("has", 1),
+	},
+	object.SET_OBJ: {
+		"has":    builtinMethod("has", 1),
+		"add":    builtinMethod("add", 1),
+		"remove": builtinMethod("remove", 1),
+	},
+}
+
+// builtinMethod makes a method out of the builtin called name, passing the
+// receiver as its first argument
+func builtinMethod(name string, arity int) method {
+	return method{
+		arity: arity,
+		fn: func(receiver object.Object, args ...object.Object) object.Object {
+			return builtins[name].Fn(append([]object.Object{receiver}, args...)...)
+		},
+	}
+}
+
+// evalMemberExpression evaluates receiver.name. A hash gives the value of
+// its "name" key if it has one, otherwise name must be a method of the
+// type of receiver, which is given back to be called. A hash without
+// either gives null, like a missing index does.
+func evalMemberExpression(receiver object.Object, name string) object.Object {
+	if value, ok := field(receiver, name); ok {
+		return value
+	}
+	if _, ok := methods[receiver.Type()][name]; ok {
+		return &object.Method{Receiver: receiver, Name: name}
+	}
+	if receiver.Type() == object.HASH_OBJ {
+		return NULL
+	}
+	return newError("unknown field %s for %s", name, receiver.Type())
+}
+
+// evalCallee evaluates the function of a call. Calling a member that is
+// neither a field nor a method is an unknown method, even on a hash where
+// reading it gives null.
+func evalCallee(callee ast.Expression, env *object.Environment) object.Object {
+	member, ok := callee.(*ast.MemberExpression)
+	if !ok {
+		return Eval(callee, env)
+	}
+	receiver := Eval(member.Object, env)
+	if isAbrupt(receiver) {
+		return receiver
+	}
+
+	name := member.Property.Value
+	if _, ok := field(receiver, name); !ok {
+		if _, ok := methods[receiver.Type()][name]; !ok {
+			return newError("unknown method %s for %s", name, receiver.Type())
+		}
+	}
+	return evalMemberExpression(receiver, name)
+}
+
+// field gives the value of the "name" key of receiver if it's a hash that
+// has one
+func field(receiver object.Object, name string) (object.Object, bool) {
+	hash, ok := receiver.(*object.Hash)
+	if !ok {
+		return nil, false
+	}
+	pair, ok := hash.Pairs[(&object.String{Value: name}).HashKey()]
+	return pair.Value, ok
+}
+
+func callMethod(m *object.Method, args []object.Object) object.Object {
+	receiverType := m.Receiver.Type()
+
+	// evalMemberExpression only makes methods that are in the table
+	entry := methods[receiverType][m.Name]
+	if len(args) != entry.arity {
+		return newError("wrong number of arguments to %s.%s: want=%d, got=%d",
+			receiverType, m.Name, entry.arity, len(args))
+	}
+	return entry.fn(m.Receiver, args...)
+}
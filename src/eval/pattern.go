@@ -0,0 +1,130 @@
+package eval
+
+import (
+	"fmt"
+
+	"github.com/psidh/Ganges/src/ast"
+	"github.com/psidh/Ganges/src/object"
+)
+
+// evalMatchExpression gives the body of the first arm that matches the
+// subject, or NULL when none does. Names bound by a pattern are only
+// visible in the guard and body of its arm.
+func evalMatchExpression(node *ast.MatchExpression, env *object.Environment) object.Object {
+	subject := Eval(node.Subject, env)
+	if isAbrupt(subject) {
+		return subject
+	}
+
+	for _, arm := range node.Arms {
+		armEnv := object.NewEnclosedEnvironment(env)
+		if mismatch := matchPattern(arm.Pattern, subject, armEnv); mismatch != "" {
+			continue
+		}
+
+		if arm.Guard != nil {
+			guard := Eval(arm.Guard, armEnv)
+			if isAbrupt(guard) {
+				return guard
+			}
+			if !isTruthy(guard) {
+				continue
+			}
+		}
+
+		return Eval(arm.Body, armEnv)
+	}
+	return NULL
+}
+
+// matchPattern checks value against pattern and binds the names in it in
+// env. It returns "" on a match, otherwise why value didn't match.
+func matchPattern(pattern ast.Expression, value object.Object, env *object.Environment) string {
+	switch pattern := pattern.(type) {
+	case *ast.Identifier:
+		if pattern.Value != "_" {
+			env.Set(pattern.Value, value)
+		}
+		return ""
+	case *ast.ArrayLiteral:
+		return matchArrayPattern(pattern, value, env)
+	case *ast.HashLiteral:
+		return matchHashPattern(pattern, value, env)
+	}
+
+	literal := Eval(pattern, env)
+	if !valuesEqual(literal, value) {
+		return fmt.Sprintf("%s does not match %s", value.Inspect(), pattern)
+	}
+	return ""
+}
+
+func matchArrayPattern(pattern *ast.ArrayLiteral, value object.Object, env *object.Environment) string {
+	array, ok := value.(*object.Array)
+	if !ok {
+		return fmt.Sprintf("expected ARRAY, got %s", value.Type())
+	}
+
+	elements := pattern.Elements
+	var rest *ast.RestElement
+	if n := len(elements); n > 0 {
+		rest, _ = elements[n-1].(*ast.RestElement)
+	}
+
+	if rest != nil {
+		elements = elements[:len(elements)-1]
+		if len(array.Elements) < len(elements) {
+			return fmt.Sprintf("expected at least %d elements, got %d", len(elements), len(array.Elements))
+		}
+	} else if len(array.Elements) != len(elements) {
+		return fmt.Sprintf("expected %d elements, got %d", len(elements), len(array.Elements))
+	}
+
+	for i, element := range elements {
+		if mismatch := matchPattern(element, array.Elements[i], env); mismatch != "" {
+			return mismatch
+		}
+	}
+
+	if rest != nil && rest.Name.Value != "_" {
+		remaining := make([]object.Object, len(array.Elements)-len(elements))
+		copy(remaining, array.Elements[len(elements):])
+		env.Set(rest.Name.Value, &object.Array{Elements: remaining})
+	}
+	return ""
+}
+
+func matchHashPattern(pattern *ast.HashLiteral, value object.Object, env *object.Environment) string {
+	hash, ok := value.(*object.Hash)
+	if !ok {
+		return fmt.Sprintf("expected HASH, got %s", value.Type())
+	}
+
+	for keyNode, valueNode := range pattern.Pairs {
+		key := Eval(keyNode, env)
+		pair, ok := hash.Pairs[key.(object.Hashable).HashKey()]
+		if !ok {
+			return fmt.Sprintf("key %s not found", key.Inspect())
+		}
+		if mismatch := matchPattern(valueNode, pair.Value, env); mismatch != "" {
+			return mismatch
+		}
+	}
+	return ""
+}
+
+// valuesEqual is == for the values a literal pattern can have
+func valuesEqual(a, b object.Object) bool {
+	if isNumber(a) && isNumber(b) {
+		if a.Type() == object.INTEGER_OBJ && b.Type() == object.INTEGER_OBJ {
+			return a.(*object.Integer).Value == b.(*object.Integer).Value
+		}
+		return toFloat(a) == toFloat(b)
+	}
+
+	if a, ok := a.(*object.String); ok {
+		b, ok := b.(*object.String)
+		return ok && a.Value == b.Value
+	}
+	return a == b
+}
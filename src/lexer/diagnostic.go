@@ -0,0 +1,116 @@
+package lexer
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/psidh/Ganges/src/token"
+)
+
+type DiagnosticKind string
+
+const (
+	UNEXPECTED_CHAR      = "UNEXPECTED_CHAR"
+	BAD_NUMBER           = "BAD_NUMBER"
+	BAD_ESCAPE           = "BAD_ESCAPE"
+	UNTERMINATED_STRING  = "UNTERMINATED_STRING"
+	UNTERMINATED_COMMENT = "UNTERMINATED_COMMENT"
+	READ_ERROR           = "READ_ERROR"
+)
+
+// Diagnostic is a problem the lexer found in the input
+type Diagnostic struct {
+	Kind       DiagnosticKind
+	Pos        token.Position
+	Message    string
+	Suggestion string // how to fix it, empty if there is nothing to suggest
+}
+
+func (d Diagnostic) String() string {
+	if d.Suggestion == "" {
+		return fmt.Sprintf("%s: %s", d.Pos, d.Message)
+	}
+	return fmt.Sprintf("%s: %s (%s)", d.Pos, d.Message, d.Suggestion)
+}
+
+func (l *Lexer) addDiagnostic(kind DiagnosticKind, pos token.Position, msg, suggestion string) {
+	l.diagnostics = append(l.diagnostics, Diagnostic{
+		Kind:       kind,
+		Pos:        pos,
+		Message:    msg,
+		Suggestion: suggestion,
+	})
+}
+
+// illegal reports ch as unexpected and returns the ILLEGAL token for it
+func (l *Lexer) illegal(ch rune) token.Token {
+	l.addDiagnostic(UNEXPECTED_CHAR, l.currPosition(),
+		fmt.Sprintf("unexpected character %q", ch), charSuggestion(ch))
+	return newToken(token.ILLEGAL, ch)
+}
+
+func charSuggestion(ch rune) string {
+	switch ch {
+	case '&':
+		return "did you mean &&?"
+	case '|':
+		return "did you mean ||?"
+	case '#':
+		return "comments start with //"
+	case '\'', '\u2018', '\u2019', '\u201c', '\u201d':
+		return `strings are written in "double quotes"`
+	case '\u00a0', '\u200b', '\ufeff':
+		return "replace it with a normal space"
+	}
+	return "remove it"
+}
+
+// checkNumber reports a number literal the parser won't be able to read.
+// Literals that are well formed but too large are left to the parser.
+func (l *Lexer) checkNumber(pos token.Position, tokenType token.TokenType, literal string) token.TokenType {
+	var err error
+	if tokenType == token.FLOAT {
+		_, err = strconv.ParseFloat(literal, 64)
+	} else if hasLeadingZero(literal) {
+		// base 0 would read 010 as octal 8, so a decimal can't start with 0
+		err = strconv.ErrSyntax
+	} else {
+		_, err = strconv.ParseInt(literal, 0, 64)
+	}
+
+	if !errors.Is(err, strconv.ErrSyntax) {
+		return tokenType
+	}
+
+	l.addDiagnostic(BAD_NUMBER, pos, fmt.Sprintf("invalid number %s", literal), numberSuggestion(literal))
+	return token.ILLEGAL
+}
+
+func numberSuggestion(literal string) string {
+	if strings.Contains(literal, "__") || strings.HasSuffix(literal, "_") || strings.Contains(literal, "_.") {
+		return "use _ only between digits"
+	}
+
+	if len(literal) > 1 && literal[0] == '0' {
+		switch strings.ToLower(literal[:2]) {
+		case "0x":
+			return "hex literals use the digits 0-9 and a-f"
+		case "0b":
+			return "binary literals use the digits 0 and 1"
+		case "0o":
+			return "octal literals use the digits 0-7"
+		}
+		if hasLeadingZero(literal) {
+			return "remove the leading 0, or write octal as 0o..."
+		}
+	}
+	return ""
+}
+
+// hasLeadingZero reports whether literal is a decimal written with a 0 in
+// front, like 010 or 0_7
+func hasLeadingZero(literal string) bool {
+	return len(literal) > 1 && literal[0] == '0' && (isDigit(rune(literal[1])) || literal[1] == '_')
+}
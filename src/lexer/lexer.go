@@ -1,45 +1,111 @@
 package lexer
 
 import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"unicode"
+	"unicode/utf8"
+
 	"github.com/psidh/Ganges/src/token"
 )
 
 type Lexer struct {
-	input        string
-	position     int
-	readPosition int
-	ch           byte
+	filename string
+
+	// the input is buf, which starts at byte offset base. When reading from
+	// an io.Reader only the part from the current token on is kept.
+	buf    []byte
+	base   int
+	reader io.Reader // nil once all input is in buf
+
+	position     int  // byte offset of l.ch
+	readPosition int  // byte offset of the rune after l.ch
+	ch           rune // 0 at the end of input
+	line         int  // line of l.ch
+	column       int  // column of l.ch, counted in runes
+	comments     []token.Token
+	diagnostics  []Diagnostic
+
+	// one entry per ${ we are inside of, counting the { opened since, so
+	// the matching } can go back to reading the rest of the string
+	interpolations []int
 }
 
 func New(input string) *Lexer {
-	l := &Lexer{input: input}
+	return NewFile("", input)
+}
+
+// NewFile is like New but records filename in the position of every token.
+func NewFile(filename, input string) *Lexer {
+	l := &Lexer{filename: filename, buf: []byte(input), line: 1}
 	l.readChar()
 	return l
 }
 
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
-		l.ch = 0
-	} else {
-		l.ch = l.input[l.readPosition]
+	if l.ch == '\n' {
+		l.line++
+		l.column = 0
 	}
 
 	l.position = l.readPosition
-	l.readPosition++
+
+	r, size := l.runeAt(l.readPosition)
+	l.ch = r
+	l.readPosition += size
+
+	l.column++
+}
+
+// currPosition returns the position of l.ch
+func (l *Lexer) currPosition() token.Position {
+	return token.Position{Filename: l.filename, Offset: l.position, Line: l.line, Column: l.column}
+}
+
+// Comments returns the comments skipped so far, in source order.
+func (l *Lexer) Comments() []token.Token {
+	return l.comments
+}
+
+// Diagnostics returns the problems found while reading the input.
+func (l *Lexer) Diagnostics() []Diagnostic {
+	return l.diagnostics
+}
+
+// Errors returns the diagnostics formatted as file:line:col: message
+func (l *Lexer) Errors() []string {
+	errors := []string{}
+	for _, d := range l.diagnostics {
+		errors = append(errors, d.String())
+	}
+	return errors
 }
 
-func (l *Lexer) peekChar() byte {
-	if l.readPosition >= len(l.input) {
-		return 0
-	} else {
-		return l.input[l.readPosition]
+func (l *Lexer) peekChar() rune {
+	return l.peekCharAt(0)
+}
+
+// peekCharAt looks offset runes past the next one without consuming
+func (l *Lexer) peekCharAt(offset int) rune {
+	position := l.readPosition
+	for ; offset > 0; offset-- {
+		_, size := l.runeAt(position)
+		position += size
 	}
+
+	r, _ := l.runeAt(position)
+	return r
 }
 func (l *Lexer) NextToken() token.Token {
 	var tok token.Token
 
 	l.skipWhitespace()
 
+	start := l.currPosition()
+	l.discard(start.Offset)
+
 	switch l.ch {
 	case ';':
 		tok = newToken(token.SEMICOLON, l.ch)
@@ -50,17 +116,90 @@ func (l *Lexer) NextToken() token.Token {
 	case ',':
 		tok = newToken(token.COMMA, l.ch)
 	case '+':
-		tok = newToken(token.PLUS, l.ch)
+		switch l.peekChar() {
+		case '+':
+			tok = l.twoCharToken(token.INCREMENT)
+		case '=':
+			tok = l.twoCharToken(token.PLUS_ASSIGN)
+		default:
+			tok = newToken(token.PLUS, l.ch)
+		}
 	case '-':
-		tok = newToken(token.MINUS, l.ch)
+		switch l.peekChar() {
+		case '-':
+			tok = l.twoCharToken(token.DECREMENT)
+		case '=':
+			tok = l.twoCharToken(token.MINUS_ASSIGN)
+		default:
+			tok = newToken(token.MINUS, l.ch)
+		}
 	case '*':
-		tok = newToken(token.ASTERISK, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.ASTERISK_ASSIGN)
+		} else {
+			tok = newToken(token.ASTERISK, l.ch)
+		}
 	case '<':
-		tok = newToken(token.LT, l.ch)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.LT_EQ, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.LT, l.ch)
+		}
 	case '>':
-		tok = newToken(token.GT, l.ch)
+		if l.peekChar() == '=' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.GT_EQ, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = newToken(token.GT, l.ch)
+		}
 	case '/':
-		tok = newToken(token.SLASH, l.ch)
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.SLASH_ASSIGN)
+		} else {
+			tok = newToken(token.SLASH, l.ch)
+		}
+	case '%':
+		if l.peekChar() == '=' {
+			tok = l.twoCharToken(token.PERCENT_ASSIGN)
+		} else {
+			tok = newToken(token.PERCENT, l.ch)
+		}
+	case '&':
+		if l.peekChar() == '&' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.AND, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.illegal(l.ch)
+		}
+	case '|':
+		if l.peekChar() == '|' {
+			ch := l.ch
+			l.readChar()
+			tok = token.Token{Type: token.OR, Literal: string(ch) + string(l.ch)}
+		} else {
+			tok = l.illegal(l.ch)
+		}
+	case '.':
+		if l.peekChar() == '.' {
+			switch l.peekCharAt(1) {
+			case '<':
+				l.readChar()
+				l.readChar()
+				tok = token.Token{Type: token.RANGE_LT, Literal: "..<"}
+			case '.':
+				l.readChar()
+				l.readChar()
+				tok = token.Token{Type: token.ELLIPSIS, Literal: "..."}
+			default:
+				tok = l.twoCharToken(token.RANGE)
+			}
+		} else {
+			tok = newToken(token.DOT, l.ch)
+		}
 	case '!':
 		if l.peekChar() == '=' {
 			ch := l.ch
@@ -71,20 +210,36 @@ func (l *Lexer) NextToken() token.Token {
 		}
 	case '{':
 		tok = newToken(token.LBRACE, l.ch)
+		if n := len(l.interpolations); n > 0 {
+			l.interpolations[n-1]++
+		}
 	case '}':
+		n := len(l.interpolations)
+		if n > 0 && l.interpolations[n-1] == 0 {
+			// end of ${...}, the string carries on
+			l.interpolations = l.interpolations[:n-1]
+			tok.Type, tok.Literal = l.readStringPart(token.VAKYA_TAIL, token.VAKYA_MIDDLE)
+			break
+		}
+		if n > 0 {
+			l.interpolations[n-1]--
+		}
 		tok = newToken(token.RBRACE, l.ch)
 	case '=':
 		if l.peekChar() == '=' {
 			ch := l.ch
 			l.readChar()
 			tok = token.Token{Type: token.EQ, Literal: string(ch) + string(l.ch)}
+		} else if l.peekChar() == '>' {
+			tok = l.twoCharToken(token.ARROW)
 		} else {
 			tok = newToken(token.ASSIGN, l.ch)
 		}
 	case '"':
-		// tok = token.Token{Type: token.VAKYA, Literal: l.readString()}
+		tok.Type, tok.Literal = l.readStringPart(token.VAKYA, token.VAKYA_HEAD)
+	case '`':
 		tok.Type = token.VAKYA
-		tok.Literal = l.readString()
+		tok.Literal = l.readRawString()
 	case '[':
 		tok = newToken(token.LBRACKET, l.ch)
 	case ']':
@@ -97,63 +252,295 @@ func (l *Lexer) NextToken() token.Token {
 		if isLetter(l.ch) {
 			tok.Literal = l.readIdentifier()
 			tok.Type = token.LookupIdent(tok.Literal)
+			tok.Pos, tok.End = start, l.currPosition()
 			return tok
 		} else if isDigit(l.ch) {
-			tok.Type = token.INT
-			tok.Literal = l.readNumber()
+			tok.Type, tok.Literal = l.readNumber()
+			tok.Type = l.checkNumber(start, tok.Type, tok.Literal)
+			tok.Pos, tok.End = start, l.currPosition()
 			return tok
 		} else {
-			tok = newToken(token.ILLEGAL, l.ch)
+			tok = l.illegal(l.ch)
 		}
 	}
 
 	l.readChar()
+	tok.Pos, tok.End = start, l.currPosition()
 	return tok
 }
 
-func (l *Lexer) readString() string {
+// readStringPart reads a string, or the rest of one after a ${...}. It
+// returns the done type if the string ends here and the interpolated type
+// if it stopped at another ${.
+func (l *Lexer) readStringPart(done, interpolated token.TokenType) (token.TokenType, string) {
+	literal, more := l.readString()
+	if more {
+		l.interpolations = append(l.interpolations, 0)
+		return interpolated, literal
+	}
+	return done, literal
+}
+
+// readString reads a "..." string and decodes its escape sequences. The
+// string may span lines and a \ at the end of a line joins it to the next.
+// It stops early, with l.ch on the {, when it finds a ${ and reports so.
+func (l *Lexer) readString() (string, bool) {
+	start := l.currPosition()
+	var out bytes.Buffer
+
+	for {
+		l.readChar()
+
+		switch l.ch {
+		case '"':
+			return out.String(), false
+		case 0:
+			l.addDiagnostic(UNTERMINATED_STRING, start, "unterminated string", `add a closing "`)
+			return out.String(), false
+		case '$':
+			if l.peekChar() == '{' {
+				l.readChar()
+				return out.String(), true
+			}
+			out.WriteRune(l.ch)
+		case '\\':
+			l.readEscape(&out)
+		default:
+			out.WriteRune(l.ch)
+		}
+	}
+}
+
+// readEscape decodes the escape sequence starting at the backslash in l.ch
+func (l *Lexer) readEscape(out *bytes.Buffer) {
+	start := l.currPosition()
+
+	// leave a dangling \ for readString to report as unterminated
+	if l.peekChar() == 0 {
+		return
+	}
+	l.readChar()
+
+	switch l.ch {
+	case '\n':
+		// a line continuation, which leaves the line break out
+	case 'n':
+		out.WriteByte('\n')
+	case 't':
+		out.WriteByte('\t')
+	case 'r':
+		out.WriteByte('\r')
+	case '"':
+		out.WriteByte('"')
+	case '$':
+		out.WriteByte('$')
+	case '\\':
+		out.WriteByte('\\')
+	case 'u':
+		l.readUnicodeEscape(start, out)
+	default:
+		l.addDiagnostic(BAD_ESCAPE, start, fmt.Sprintf("unknown escape sequence \\%c", l.ch),
+			`use \n, \t, \", \\, \$ or \u{...}`)
+		out.WriteRune(l.ch)
+	}
+}
+
+// readUnicodeEscape reads the {hex} part of a \u{...} escape
+func (l *Lexer) readUnicodeEscape(start token.Position, out *bytes.Buffer) {
+	if l.peekChar() != '{' {
+		l.addDiagnostic(BAD_ESCAPE, start, "invalid unicode escape", `write it as \u{hex digits}, e.g. \u{0939}`)
+		return
+	}
+	l.readChar()
+
+	position := l.position + 1
+	for isHexDigit(l.peekChar()) {
+		l.readChar()
+	}
+	digits := l.slice(position, l.position+1)
+
+	if l.peekChar() != '}' || digits == "" {
+		l.addDiagnostic(BAD_ESCAPE, start, "invalid unicode escape", `write it as \u{hex digits}, e.g. \u{0939}`)
+		return
+	}
+	l.readChar()
+
+	code, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || !utf8.ValidRune(rune(code)) {
+		l.addDiagnostic(BAD_ESCAPE, start, fmt.Sprintf("invalid unicode code point \\u{%s}", digits),
+			"code points go up to 10FFFF and exclude surrogates")
+		return
+	}
+	out.WriteRune(rune(code))
+}
+
+// readRawString reads a `...` string as is. It may span several lines and
+// has no escape sequences.
+func (l *Lexer) readRawString() string {
+	start := l.currPosition()
 	position := l.position + 1
 
 	for {
 		l.readChar()
 
-		if l.ch == '"' || l.ch == 0 {
+		if l.ch == '`' {
 			break
 		}
 
+		if l.ch == 0 {
+			l.addDiagnostic(UNTERMINATED_STRING, start, "unterminated raw string", "add a closing `")
+			break
+		}
 	}
-	return l.input[position:l.position]
+	return l.slice(position, l.position)
 }
 func (l *Lexer) readIdentifier() string {
 	position := l.position
-	for isLetter(l.ch) {
+	for isLetter(l.ch) || unicode.IsMark(l.ch) {
 		l.readChar()
 	}
-	return l.input[position:l.position]
+	return l.slice(position, l.position)
 }
 
-func (l *Lexer) readNumber() string {
+// readNumber reads an integer or a float with an optional fraction and
+// exponent, e.g. 42, 3.14, 2.5e10, 1e-9. Integers may also be written in
+// hex, binary or octal (0xff, 0b1010, 0o755) and any number may use _ to
+// group digits (1_000_000).
+func (l *Lexer) readNumber() (token.TokenType, string) {
 	position := l.position
-	for isDigit(l.ch) {
+	var tokenType token.TokenType = token.INT
+
+	if l.ch == '0' && isBasePrefix(l.peekChar()) {
 		l.readChar()
+		l.readChar()
+		// read anything that could belong to the literal, so a typo like
+		// 0b102 is reported as one bad number instead of two tokens
+		for isLetter(l.ch) || isDigit(l.ch) {
+			l.readChar()
+		}
+		return tokenType, l.slice(position, l.position)
+	}
+
+	l.readDigits()
+
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		tokenType = token.FLOAT
+		l.readChar()
+		l.readDigits()
 	}
-	return l.input[position:l.position]
+
+	if l.ch == 'e' || l.ch == 'E' {
+		next := l.peekChar()
+		if isDigit(next) || (next == '+' || next == '-') && isDigit(l.peekCharAt(1)) {
+			tokenType = token.FLOAT
+			l.readChar()
+			if l.ch == '+' || l.ch == '-' {
+				l.readChar()
+			}
+			l.readDigits()
+		}
+	}
+
+	return tokenType, l.slice(position, l.position)
 }
 
-func isLetter(ch byte) bool {
-	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
+func (l *Lexer) readDigits() {
+	for isDigit(l.ch) || l.ch == '_' {
+		l.readChar()
+	}
+}
+
+func isBasePrefix(ch rune) bool {
+	switch ch {
+	case 'x', 'X', 'b', 'B', 'o', 'O':
+		return true
+	}
+	return false
 }
 
-func isDigit(ch byte) bool {
+// isLetter accepts any Unicode letter, so identifiers can be written in
+// Devanagari. Vowel signs and viramas are marks, not letters, and are only
+// allowed after the first letter (see readIdentifier).
+func isLetter(ch rune) bool {
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_' ||
+		ch >= utf8.RuneSelf && unicode.IsLetter(ch)
+}
+
+func isDigit(ch rune) bool {
 	return '0' <= ch && ch <= '9'
 }
 
-func newToken(tokenType token.TokenType, ch byte) token.Token {
+func isHexDigit(ch rune) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
+}
+
+// twoCharToken returns a token of type t made of l.ch and the char after it
+func (l *Lexer) twoCharToken(t token.TokenType) token.Token {
+	ch := l.ch
+	l.readChar()
+	return token.Token{Type: t, Literal: string(ch) + string(l.ch)}
+}
+
+func newToken(tokenType token.TokenType, ch rune) token.Token {
 	return token.Token{Type: tokenType, Literal: string(ch)}
 }
 
+// skipWhitespace skips blanks as well as // and /* */ comments
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
+	for {
+		switch {
+		case l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r':
+			l.readChar()
+		case l.ch == '/' && l.peekChar() == '/':
+			l.skipLineComment()
+		case l.ch == '/' && l.peekChar() == '*':
+			l.skipBlockComment()
+		default:
+			return
+		}
+	}
+}
+
+func (l *Lexer) skipLineComment() {
+	start := l.currPosition()
+	for l.ch != '\n' && l.ch != 0 {
+		l.readChar()
+	}
+	l.addComment(start)
+}
+
+// skipBlockComment skips a /* */ comment, which may contain nested ones
+func (l *Lexer) skipBlockComment() {
+	start := l.currPosition()
+	depth := 0
+
+	for {
+		switch {
+		case l.ch == 0:
+			l.addDiagnostic(UNTERMINATED_COMMENT, start, "unterminated block comment", "add a closing */")
+			l.addComment(start)
+			return
+		case l.ch == '/' && l.peekChar() == '*':
+			depth++
+			l.readChar()
+		case l.ch == '*' && l.peekChar() == '/':
+			depth--
+			l.readChar()
+			if depth == 0 {
+				l.readChar()
+				l.addComment(start)
+				return
+			}
+		}
 		l.readChar()
 	}
 }
+
+func (l *Lexer) addComment(start token.Position) {
+	l.comments = append(l.comments, token.Token{
+		Type:    token.COMMENT,
+		Literal: l.slice(start.Offset, l.position),
+		Pos:     start,
+		End:     l.currPosition(),
+	})
+}
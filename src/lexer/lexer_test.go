@@ -1,7 +1,9 @@
 package lexer
 
 import (
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/psidh/Ganges/src/token"
 )
@@ -17,7 +19,7 @@ rama add = kriya(x, y) {
 x + y;
 };
 rama result = add(five, ten);
-!-/*5;
+!-/ *5;
 5 < 10 > 5;
 yadi (5 < 10) {
 daan satya;
@@ -164,3 +166,628 @@ chakra (i < 10) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	input := "rama x = 5;\n  vadha(\"hi\");"
+
+	tests := []struct {
+		expectedType   token.TokenType
+		expectedLine   int
+		expectedColumn int
+		expectedOffset int
+		expectedEndCol int
+	}{
+		{token.RAMA, 1, 1, 0, 5},
+		{token.IDENT, 1, 6, 5, 7},
+		{token.ASSIGN, 1, 8, 7, 9},
+		{token.INT, 1, 10, 9, 11},
+		{token.SEMICOLON, 1, 11, 10, 12},
+		{token.IDENT, 2, 3, 14, 8},
+		{token.LPAREN, 2, 8, 19, 9},
+		{token.VAKYA, 2, 9, 20, 13},
+		{token.RPAREN, 2, 13, 24, 14},
+		{token.SEMICOLON, 2, 14, 25, 15},
+		{token.EOF, 2, 15, 26, 16},
+	}
+
+	l := NewFile("main.ga", input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+
+		if tok.Type != tt.expectedType {
+			t.Fatalf("tests[%d] - tokenType wrong. expected=%q, got=%q", i, tt.expectedType, tok.Type)
+		}
+		if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Pos.Line, tok.Pos.Column)
+		}
+		if tok.Pos.Offset != tt.expectedOffset {
+			t.Errorf("tests[%d] - offset wrong. expected=%d, got=%d", i, tt.expectedOffset, tok.Pos.Offset)
+		}
+		if tok.End.Column != tt.expectedEndCol {
+			t.Errorf("tests[%d] - end column wrong. expected=%d, got=%d", i, tt.expectedEndCol, tok.End.Column)
+		}
+		if tok.Pos.Filename != "main.ga" {
+			t.Errorf("tests[%d] - filename wrong. got=%q", i, tok.Pos.Filename)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := `rama x = 10; // thirty
+/* a /* nested */ block */ x
+// last`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.RAMA, "rama"},
+		{token.IDENT, "x"},
+		{token.ASSIGN, "="},
+		{token.INT, "10"},
+		{token.SEMICOLON, ";"},
+		{token.IDENT, "x"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+
+	expectedComments := []string{"// thirty", "/* a /* nested */ block */", "// last"}
+	comments := l.Comments()
+	if len(comments) != len(expectedComments) {
+		t.Fatalf("wrong number of comments. expected=%d, got=%d", len(expectedComments), len(comments))
+	}
+	for i, c := range comments {
+		if c.Literal != expectedComments[i] {
+			t.Errorf("comments[%d] wrong. expected=%q, got=%q", i, expectedComments[i], c.Literal)
+		}
+	}
+	if comments[1].Pos.Line != 2 || comments[1].Pos.Column != 1 {
+		t.Errorf("comments[1] position wrong. got=%s", comments[1].Pos)
+	}
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected lexer errors: %v", l.Errors())
+	}
+}
+
+func TestUnterminatedBlockComment(t *testing.T) {
+	l := New("rama x = 1;\n/* /* */ never closed")
+
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+	}
+
+	errors := l.Errors()
+	if len(errors) != 1 {
+		t.Fatalf("expected 1 lexer error, got=%d (%v)", len(errors), errors)
+	}
+	if errors[0] != "2:1: unterminated block comment (add a closing */)" {
+		t.Errorf("wrong error. got=%q", errors[0])
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	input := `42 3.14 0.5 2.5e10 1e-9 6E+2 7.x 1e`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "42"},
+		{token.FLOAT, "3.14"},
+		{token.FLOAT, "0.5"},
+		{token.FLOAT, "2.5e10"},
+		{token.FLOAT, "1e-9"},
+		{token.FLOAT, "6E+2"},
+		{token.INT, "7"},
+		{token.DOT, "."},
+		{token.IDENT, "x"},
+		{token.INT, "1"},
+		{token.IDENT, "e"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	input := `"a\nb" "tab\there" "say \"hi\"" "back\\slash" "\u{930}\u{93E}\u{92E}" ` +
+		"\"two\nlines\" \"joined \\\nhere\" `raw \\n {\"k\": 1}\nsecond line`"
+
+	expected := []string{
+		"a\nb",
+		"tab\there",
+		`say "hi"`,
+		`back\slash`,
+		"राम",
+		"two\nlines",
+		"joined here",
+		"raw \\n {\"k\": 1}\nsecond line",
+	}
+
+	l := New(input)
+
+	for i, want := range expected {
+		tok := l.NextToken()
+		if tok.Type != token.VAKYA {
+			t.Fatalf("tests[%d] - tokenType wrong. expected=%q, got=%q", i, token.VAKYA, tok.Type)
+		}
+		if tok.Literal != want {
+			t.Errorf("tests[%d] - literal wrong. expected=%q, got=%q", i, want, tok.Literal)
+		}
+	}
+
+	tok := l.NextToken()
+	if tok.Type != token.EOF {
+		t.Errorf("expected EOF, got=%q", tok.Type)
+	}
+	if tok.Pos.Line != 4 {
+		t.Errorf("string newlines not counted, EOF on line %d", tok.Pos.Line)
+	}
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected lexer errors: %v", l.Errors())
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`rama s = "never closed;`, "1:10: unterminated string (add a closing \")"},
+		{"\"first line\nrama x = 1;", "1:1: unterminated string (add a closing \")"},
+		{`"dangling \`, "1:1: unterminated string (add a closing \")"},
+		{`"bad \q escape"`, `1:6: unknown escape sequence \q (use \n, \t, \", \\, \$ or \u{...})`},
+		{`"\u{zz}"`, `1:2: invalid unicode escape (write it as \u{hex digits}, e.g. \u{0939})`},
+		{`"\u{110000}"`, `1:2: invalid unicode code point \u{110000} (code points go up to 10FFFF and exclude surrogates)`},
+		{"`raw", "1:1: unterminated raw string (add a closing `)"},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+
+		errors := l.Errors()
+		if len(errors) != 1 {
+			t.Errorf("%q: expected 1 error, got=%v", tt.input, errors)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("%q: wrong error. expected=%q, got=%q", tt.input, tt.expected, errors[0])
+		}
+	}
+}
+
+func TestDevanagari(t *testing.T) {
+	input := `राम संख्या = 5;
+यदि (सत्य) { दान क्रिया(x) { x }; } अन्यथा { असत्य }
+चक्र (ü) {}`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+		expectedLine    int
+		expectedColumn  int
+	}{
+		{token.RAMA, "राम", 1, 1},
+		{token.IDENT, "संख्या", 1, 5},
+		{token.ASSIGN, "=", 1, 12},
+		{token.INT, "5", 1, 14},
+		{token.SEMICOLON, ";", 1, 15},
+		{token.YADI, "यदि", 2, 1},
+		{token.LPAREN, "(", 2, 5},
+		{token.SATYA, "सत्य", 2, 6},
+		{token.RPAREN, ")", 2, 10},
+		{token.LBRACE, "{", 2, 12},
+		{token.DAAN, "दान", 2, 14},
+		{token.KRIYA, "क्रिया", 2, 18},
+		{token.LPAREN, "(", 2, 24},
+		{token.IDENT, "x", 2, 25},
+		{token.RPAREN, ")", 2, 26},
+		{token.LBRACE, "{", 2, 28},
+		{token.IDENT, "x", 2, 30},
+		{token.RBRACE, "}", 2, 32},
+		{token.SEMICOLON, ";", 2, 33},
+		{token.RBRACE, "}", 2, 35},
+		{token.ANYATHA, "अन्यथा", 2, 37},
+		{token.LBRACE, "{", 2, 44},
+		{token.ASATYA, "असत्य", 2, 46},
+		{token.RBRACE, "}", 2, 52},
+		{token.CHAKRA, "चक्र", 3, 1},
+		{token.LPAREN, "(", 3, 6},
+		{token.IDENT, "ü", 3, 7},
+		{token.RPAREN, ")", 3, 8},
+		{token.LBRACE, "{", 3, 10},
+		{token.RBRACE, "}", 3, 11},
+		{token.EOF, "", 3, 12},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+		if tok.Pos.Line != tt.expectedLine || tok.Pos.Column != tt.expectedColumn {
+			t.Errorf("tests[%d] - position wrong. expected=%d:%d, got=%d:%d",
+				i, tt.expectedLine, tt.expectedColumn, tok.Pos.Line, tok.Pos.Column)
+		}
+	}
+}
+
+func TestLoopKeywords(t *testing.T) {
+	input := `viram; agla; विराम अगला viramx pratyek प्रत्येक`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.VIRAM, "viram"},
+		{token.SEMICOLON, ";"},
+		{token.AGLA, "agla"},
+		{token.SEMICOLON, ";"},
+		{token.VIRAM, "विराम"},
+		{token.AGLA, "अगला"},
+		{token.IDENT, "viramx"},
+		{token.PRATYEK, "pratyek"},
+		{token.PRATYEK, "प्रत्येक"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestIllegalRune(t *testing.T) {
+	l := New("x € y")
+
+	l.NextToken()
+	tok := l.NextToken()
+	if tok.Type != token.ILLEGAL || tok.Literal != "€" {
+		t.Fatalf("wrong token. expected=ILLEGAL %q, got=%q %q", "€", tok.Type, tok.Literal)
+	}
+	if tok.Pos.Column != 3 || tok.End.Column != 4 {
+		t.Errorf("wrong columns. got=%d-%d", tok.Pos.Column, tok.End.Column)
+	}
+	if tok := l.NextToken(); tok.Pos.Column != 5 || tok.Pos.Offset != 6 {
+		t.Errorf("wrong position after rune. got=col %d offset %d", tok.Pos.Column, tok.Pos.Offset)
+	}
+}
+
+func TestComparisonAndLogicalOperators(t *testing.T) {
+	input := `a <= b >= c % d && e || f & |`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.LT_EQ, "<="},
+		{token.IDENT, "b"},
+		{token.GT_EQ, ">="},
+		{token.IDENT, "c"},
+		{token.PERCENT, "%"},
+		{token.IDENT, "d"},
+		{token.AND, "&&"},
+		{token.IDENT, "e"},
+		{token.OR, "||"},
+		{token.IDENT, "f"},
+		{token.ILLEGAL, "&"},
+		{token.ILLEGAL, "|"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestAssignmentOperators(t *testing.T) {
+	input := `a += 1 -= b *= c /= d %= e++ --f - -g`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.IDENT, "a"},
+		{token.PLUS_ASSIGN, "+="},
+		{token.INT, "1"},
+		{token.MINUS_ASSIGN, "-="},
+		{token.IDENT, "b"},
+		{token.ASTERISK_ASSIGN, "*="},
+		{token.IDENT, "c"},
+		{token.SLASH_ASSIGN, "/="},
+		{token.IDENT, "d"},
+		{token.PERCENT_ASSIGN, "%="},
+		{token.IDENT, "e"},
+		{token.INCREMENT, "++"},
+		{token.DECREMENT, "--"},
+		{token.IDENT, "f"},
+		{token.MINUS, "-"},
+		{token.MINUS, "-"},
+		{token.IDENT, "g"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestRangeAndMatchTokens(t *testing.T) {
+	input := `1..10 0..<n 1.5..2 x.y kadam => milana ...rest`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "1"},
+		{token.RANGE, ".."},
+		{token.INT, "10"},
+		{token.INT, "0"},
+		{token.RANGE_LT, "..<"},
+		{token.IDENT, "n"},
+		{token.FLOAT, "1.5"},
+		{token.RANGE, ".."},
+		{token.INT, "2"},
+		{token.IDENT, "x"},
+		{token.DOT, "."},
+		{token.IDENT, "y"},
+		{token.KADAM, "kadam"},
+		{token.ARROW, "=>"},
+		{token.MILANA, "milana"},
+		{token.ELLIPSIS, "..."},
+		{token.IDENT, "rest"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestIntegerLiteralForms(t *testing.T) {
+	input := `0xff 0XAB_CD 0b1010 0o755 1_000_000 0b102 1_000.5 0x`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.INT, "0xff"},
+		{token.INT, "0XAB_CD"},
+		{token.INT, "0b1010"},
+		{token.INT, "0o755"},
+		{token.INT, "1_000_000"},
+		{token.ILLEGAL, "0b102"},
+		{token.FLOAT, "1_000.5"},
+		{token.ILLEGAL, "0x"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestInterpolatedString(t *testing.T) {
+	input := `"Namaste, ${name}! ${ {"a": 1}["a"] } \${not} ${"in ${x}"}" "$5"`
+
+	tests := []struct {
+		expectedType    token.TokenType
+		expectedLiteral string
+	}{
+		{token.VAKYA_HEAD, "Namaste, "},
+		{token.IDENT, "name"},
+		{token.VAKYA_MIDDLE, "! "},
+		{token.LBRACE, "{"},
+		{token.VAKYA, "a"},
+		{token.COLON, ":"},
+		{token.INT, "1"},
+		{token.RBRACE, "}"},
+		{token.LBRACKET, "["},
+		{token.VAKYA, "a"},
+		{token.RBRACKET, "]"},
+		{token.VAKYA_MIDDLE, " ${not} "},
+		{token.VAKYA_HEAD, "in "},
+		{token.IDENT, "x"},
+		{token.VAKYA_TAIL, ""},
+		{token.VAKYA_TAIL, ""},
+		{token.VAKYA, "$5"},
+		{token.EOF, ""},
+	}
+
+	l := New(input)
+
+	for i, tt := range tests {
+		tok := l.NextToken()
+		if tok.Type != tt.expectedType || tok.Literal != tt.expectedLiteral {
+			t.Fatalf("tests[%d] - wrong token. expected=%q %q, got=%q %q",
+				i, tt.expectedType, tt.expectedLiteral, tok.Type, tok.Literal)
+		}
+	}
+
+	if len(l.Errors()) != 0 {
+		t.Errorf("unexpected lexer errors: %v", l.Errors())
+	}
+}
+
+func TestNewReaderMatchesNew(t *testing.T) {
+	input := `राम संख्या = 0x1f + 2.5e3; // comment
+/* block /* nested */ */
+rama greet = kriya(name) { "Namaste, ${name}!\t\u{1F30A}" };
+` + "`raw\nstring`" + `
+chakra (i <= 10 && !done) { i = i % 3; }
+"unterminated
+€`
+
+	expected := New(input)
+	streamed := NewReader(iotest.OneByteReader(strings.NewReader(input)))
+
+	for i := 0; ; i++ {
+		want := expected.NextToken()
+		got := streamed.NextToken()
+
+		if got != want {
+			t.Fatalf("token[%d] differs. New=%+v, NewReader=%+v", i, want, got)
+		}
+		if want.Type == token.EOF {
+			break
+		}
+	}
+
+	if strings.Join(expected.Errors(), "\n") != strings.Join(streamed.Errors(), "\n") {
+		t.Errorf("errors differ. New=%v, NewReader=%v", expected.Errors(), streamed.Errors())
+	}
+	if len(expected.Comments()) != len(streamed.Comments()) {
+		t.Errorf("comments differ. New=%v, NewReader=%v", expected.Comments(), streamed.Comments())
+	}
+}
+
+func TestNewReaderKeepsBufferSmall(t *testing.T) {
+	line := "rama x = [1, 2, 3]; // some padding to make the line longer\n"
+	lines := 20000
+	l := NewFileReader("big.ga", strings.NewReader(strings.Repeat(line, lines)))
+
+	count := 0
+	var last token.Token
+	for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		count++
+		last = tok
+	}
+
+	if count != 11*lines {
+		t.Errorf("wrong number of tokens. expected=%d, got=%d", 11*lines, count)
+	}
+	lastOffset := len(line)*(lines-1) + strings.Index(line, ";")
+	if last.Pos.Line != lines || last.Pos.Offset != lastOffset {
+		t.Errorf("wrong position of last token. got=%s offset %d", last.Pos, last.Pos.Offset)
+	}
+	if last.Pos.Filename != "big.ga" {
+		t.Errorf("wrong filename. got=%q", last.Pos.Filename)
+	}
+	if cap(l.buf) > 4*chunkSize {
+		t.Errorf("buffer grew with the input. cap=%d", cap(l.buf))
+	}
+}
+
+func TestNewReaderError(t *testing.T) {
+	r := iotest.TimeoutReader(iotest.OneByteReader(strings.NewReader("rama x = 5;")))
+	l := NewReader(r)
+
+	tok := l.NextToken()
+	if tok.Type != token.IDENT || tok.Literal != "r" {
+		t.Fatalf("wrong token. expected=IDENT \"r\", got=%q %q", tok.Type, tok.Literal)
+	}
+	if tok := l.NextToken(); tok.Type != token.EOF {
+		t.Fatalf("expected EOF after read error, got=%q", tok.Type)
+	}
+
+	errs := l.Errors()
+	if len(errs) != 1 || !strings.Contains(errs[0], "could not read input: "+iotest.ErrTimeout.Error()) {
+		t.Errorf("expected read error, got=%v", errs)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	tests := []struct {
+		input              string
+		expectedKind       DiagnosticKind
+		expectedLine       int
+		expectedColumn     int
+		expectedMessage    string
+		expectedSuggestion string
+	}{
+		{"rama x = 5 @ 3;", UNEXPECTED_CHAR, 1, 12, `unexpected character '@'`, "remove it"},
+		{"a & b", UNEXPECTED_CHAR, 1, 3, `unexpected character '&'`, "did you mean &&?"},
+		{"a | b", UNEXPECTED_CHAR, 1, 3, `unexpected character '|'`, "did you mean ||?"},
+		{"# note", UNEXPECTED_CHAR, 1, 1, `unexpected character '#'`, "comments start with //"},
+		{"rama s = 'x;", UNEXPECTED_CHAR, 1, 10, `unexpected character '\''`, `strings are written in "double quotes"`},
+		{"x =\u00a01", UNEXPECTED_CHAR, 1, 4, `unexpected character '\u00a0'`, "replace it with a normal space"},
+		{"\n  0b12", BAD_NUMBER, 2, 3, "invalid number 0b12", "binary literals use the digits 0 and 1"},
+		{"0xfg", BAD_NUMBER, 1, 1, "invalid number 0xfg", "hex literals use the digits 0-9 and a-f"},
+		{"0o8", BAD_NUMBER, 1, 1, "invalid number 0o8", "octal literals use the digits 0-7"},
+		{"1__000", BAD_NUMBER, 1, 1, "invalid number 1__000", "use _ only between digits"},
+		{"09", BAD_NUMBER, 1, 1, "invalid number 09", "remove the leading 0, or write octal as 0o..."},
+		{"010", BAD_NUMBER, 1, 1, "invalid number 010", "remove the leading 0, or write octal as 0o..."},
+		{"0_7", BAD_NUMBER, 1, 1, "invalid number 0_7", "remove the leading 0, or write octal as 0o..."},
+		{`"abc`, UNTERMINATED_STRING, 1, 1, "unterminated string", "add a closing \""},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		for tok := l.NextToken(); tok.Type != token.EOF; tok = l.NextToken() {
+		}
+
+		diagnostics := l.Diagnostics()
+		if len(diagnostics) != 1 {
+			t.Errorf("%q: expected 1 diagnostic, got=%v", tt.input, diagnostics)
+			continue
+		}
+
+		d := diagnostics[0]
+		if d.Kind != tt.expectedKind {
+			t.Errorf("%q: wrong kind. expected=%s, got=%s", tt.input, tt.expectedKind, d.Kind)
+		}
+		if d.Pos.Line != tt.expectedLine || d.Pos.Column != tt.expectedColumn {
+			t.Errorf("%q: wrong position. expected=%d:%d, got=%s",
+				tt.input, tt.expectedLine, tt.expectedColumn, d.Pos)
+		}
+		if d.Message != tt.expectedMessage {
+			t.Errorf("%q: wrong message. expected=%q, got=%q", tt.input, tt.expectedMessage, d.Message)
+		}
+		if d.Suggestion != tt.expectedSuggestion {
+			t.Errorf("%q: wrong suggestion. expected=%q, got=%q", tt.input, tt.expectedSuggestion, d.Suggestion)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package lexer
+
+import (
+	"fmt"
+	"io"
+	"unicode/utf8"
+)
+
+// chunkSize is how much is read from an io.Reader at a time
+const chunkSize = 4096
+
+// NewReader returns a Lexer that reads its input from r as tokens are
+// asked for, so the whole program never has to be in memory at once.
+// It produces the same tokens and positions as New.
+func NewReader(r io.Reader) *Lexer {
+	return NewFileReader("", r)
+}
+
+// NewFileReader is like NewReader but records filename in the position of
+// every token.
+func NewFileReader(filename string, r io.Reader) *Lexer {
+	l := &Lexer{filename: filename, reader: r, line: 1}
+	l.readChar()
+	return l
+}
+
+// fill makes sure the buffer holds the byte at offset and, when there is
+// input left, a whole rune starting there. It reports whether offset is
+// before the end of the input.
+func (l *Lexer) fill(offset int) bool {
+	for l.reader != nil && offset+utf8.UTFMax > l.base+len(l.buf) {
+		l.readMore()
+	}
+	return offset < l.base+len(l.buf)
+}
+
+func (l *Lexer) readMore() {
+	if cap(l.buf)-len(l.buf) < chunkSize {
+		buf := make([]byte, len(l.buf), 2*cap(l.buf)+chunkSize)
+		copy(buf, l.buf)
+		l.buf = buf
+	}
+
+	n, err := l.reader.Read(l.buf[len(l.buf):cap(l.buf)])
+	l.buf = l.buf[:len(l.buf)+n]
+
+	if err != nil {
+		if err != io.EOF {
+			l.addDiagnostic(READ_ERROR, l.currPosition(), fmt.Sprintf("could not read input: %s", err), "")
+		}
+		l.reader = nil
+	}
+}
+
+// runeAt decodes the rune starting at offset, size is 0 at the end of input
+func (l *Lexer) runeAt(offset int) (rune, int) {
+	if !l.fill(offset) {
+		return 0, 0
+	}
+	return utf8.DecodeRune(l.buf[offset-l.base:])
+}
+
+// slice returns the input between two offsets that are still buffered
+func (l *Lexer) slice(from, to int) string {
+	return string(l.buf[from-l.base : to-l.base])
+}
+
+// discard lets go of the input before offset once nothing refers to it
+// anymore. The buffer is only compacted once most of it is stale, so each
+// byte is copied a bounded number of times.
+func (l *Lexer) discard(offset int) {
+	stale := offset - l.base
+	if stale < chunkSize || stale < len(l.buf)/2 {
+		return
+	}
+	l.buf = append(l.buf[:0], l.buf[stale:]...)
+	l.base = offset
+}
@@ -15,6 +15,18 @@ func (e *Environment) Set(name string, val Object) Object {
 	return val
 }
 
+// Assign updates name in the scope that declared it. It reports false,
+// and changes nothing, when name was never declared.
+func (e *Environment) Assign(name string, val Object) bool {
+	for env := e; env != nil; env = env.outer {
+		if _, ok := env.store[name]; ok {
+			env.store[name] = val
+			return true
+		}
+	}
+	return false
+}
+
 func (e *Environment) Get(name string) (Object, bool) {
 	obj, ok := e.store[name]
 	if !ok && e.outer != nil {
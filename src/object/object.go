@@ -4,9 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"hash/fnv"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/psidh/Ganges/src/ast"
+	"github.com/psidh/Ganges/src/token"
 )
 
 type ObjectType string
@@ -24,6 +28,10 @@ type Integer struct {
 	Value int64
 }
 
+type Float struct {
+	Value float64
+}
+
 type Boolean struct {
 	Value bool
 }
@@ -33,12 +41,20 @@ type Null struct {
 
 type Error struct {
 	Message string
+	Pos     token.Position // where in the source the error was raised
 }
 type ReturnValue struct {
 	Value Object
 }
+
+// Break and Continue carry viram and agla out of the blocks of a loop body
+type Break struct{}
+type Continue struct{}
 type Function struct {
+	Name       string
 	Parameters []*ast.Identifier
+	Defaults   map[string]ast.Expression
+	Rest       *ast.Identifier
 	Body       *ast.BlockStatement
 	Env        *Environment
 }
@@ -47,6 +63,13 @@ type String struct {
 	Value string
 }
 
+// Method is a method looked up on a value, as in arr.push, waiting to be
+// called with the value as its receiver
+type Method struct {
+	Receiver Object
+	Name     string
+}
+
 type BuiltinFunction func(args ...Object) Object
 type Builtin struct {
 	Fn BuiltinFunction
@@ -55,6 +78,15 @@ type Array struct {
 	Elements []Object
 }
 
+// Range is the integers from From up to To, stepping by Step. It is
+// lazy, its elements are only worked out when they are asked for.
+type Range struct {
+	From      int64
+	To        int64
+	Step      int64
+	Inclusive bool // whether To itself can be an element
+}
+
 type HashPair struct {
 	Key   Object
 	Value Object
@@ -86,6 +118,64 @@ type Set struct {
 	Elements map[HashKey]Object
 }
 
+// SortedPairs returns the pairs of h ordered by key, see Less
+func (h *Hash) SortedPairs() []HashPair {
+	pairs := make([]HashPair, 0, len(h.Pairs))
+	for _, pair := range h.Pairs {
+		pairs = append(pairs, pair)
+	}
+	sort.Slice(pairs, func(i, j int) bool { return Less(pairs[i].Key, pairs[j].Key) })
+	return pairs
+}
+
+// SortedElements returns the elements of s in order, see Less
+func (s *Set) SortedElements() []Object {
+	elements := make([]Object, 0, len(s.Elements))
+	for _, element := range s.Elements {
+		elements = append(elements, element)
+	}
+	sort.Slice(elements, func(i, j int) bool { return Less(elements[i], elements[j]) })
+	return elements
+}
+
+// Less orders hash keys so hashes and sets can be walked the same way every
+// time. Numbers come first by value, then asatya before satya, then strings.
+func Less(a, b Object) bool {
+	rank := func(o Object) int {
+		switch o.(type) {
+		case *Integer, *Float:
+			return 0
+		case *Boolean:
+			return 1
+		case *String:
+			return 2
+		}
+		return 3
+	}
+
+	if rank(a) != rank(b) {
+		return rank(a) < rank(b)
+	}
+
+	switch a := a.(type) {
+	case *Integer:
+		if b, ok := b.(*Integer); ok {
+			return a.Value < b.Value
+		}
+		return float64(a.Value) < b.(*Float).Value
+	case *Float:
+		if b, ok := b.(*Integer); ok {
+			return a.Value < float64(b.Value)
+		}
+		return a.Value < b.(*Float).Value
+	case *Boolean:
+		return !a.Value && b.(*Boolean).Value
+	case *String:
+		return a.Value < b.(*String).Value
+	}
+	return a.Inspect() < b.Inspect()
+}
+
 func (s *Set) Type() ObjectType { return SET_OBJ }
 
 func (s *Set) Inspect() string {
@@ -100,9 +190,67 @@ func (s *Set) Inspect() string {
 	return out.String()
 }
 
+func (r *Range) Type() ObjectType { return RANGE_OBJ }
+func (r *Range) Inspect() string {
+	op := "..<"
+	if r.Inclusive {
+		op = ".."
+	}
+	if r.Step == 1 {
+		return fmt.Sprintf("%d%s%d", r.From, op, r.To)
+	}
+	return fmt.Sprintf("%d%s%d kadam %d", r.From, op, r.To, r.Step)
+}
+
+// Len is how many elements r has
+func (r *Range) Len() int64 {
+	var distance, step uint64
+	switch {
+	case r.Step > 0 && r.To >= r.From:
+		distance, step = uint64(r.To)-uint64(r.From), uint64(r.Step)
+	case r.Step < 0 && r.To <= r.From:
+		distance, step = uint64(r.From)-uint64(r.To), -uint64(r.Step)
+	default:
+		return 0
+	}
+
+	if !r.Inclusive {
+		if distance == 0 {
+			return 0
+		}
+		distance--
+	}
+	return int64(distance/step) + 1
+}
+
+// At is the element at index i, which must be in [0, Len())
+func (r *Range) At(i int64) int64 { return r.From + i*r.Step }
+
+// Contains reports whether n is one of the elements of r
+func (r *Range) Contains(n int64) bool {
+	if r.Len() == 0 {
+		return false
+	}
+	last := r.At(r.Len() - 1)
+	if r.Step > 0 && (n < r.From || n > last) || r.Step < 0 && (n > r.From || n < last) {
+		return false
+	}
+	return (n-r.From)%r.Step == 0
+}
+
 func (i *Integer) Inspect() string  { return fmt.Sprintf("%d", i.Value) }
 func (i *Integer) Type() ObjectType { return INTEGER_OBJ }
 
+// Inspect always shows a fraction or exponent so 3.0 doesn't read as 3
+func (f *Float) Inspect() string {
+	s := strconv.FormatFloat(f.Value, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eIN") {
+		s += ".0"
+	}
+	return s
+}
+func (f *Float) Type() ObjectType { return FLOAT_OBJ }
+
 func (b *Boolean) Inspect() string  { return fmt.Sprintf("%t", b.Value) }
 func (b *Boolean) Type() ObjectType { return BOOLEAN_OBJ }
 
@@ -112,7 +260,17 @@ func (n *Null) Type() ObjectType { return NULL_OBJ }
 func (rv *ReturnValue) Inspect() string  { return rv.Value.Inspect() }
 func (rv *ReturnValue) Type() ObjectType { return RETURN_VALUE_OBJ }
 
-func (e *Error) Inspect() string  { return "ERROR: " + e.Message }
+func (b *Break) Inspect() string     { return "viram" }
+func (b *Break) Type() ObjectType    { return BREAK_OBJ }
+func (c *Continue) Inspect() string  { return "agla" }
+func (c *Continue) Type() ObjectType { return CONTINUE_OBJ }
+
+func (e *Error) Inspect() string {
+	if e.Pos.IsValid() {
+		return "ERROR: " + e.Pos.String() + ": " + e.Message
+	}
+	return "ERROR: " + e.Message
+}
 func (e *Error) Type() ObjectType { return ERROR_OBJ }
 
 func (f *Function) Type() ObjectType { return FUNCTION_OBJ }
@@ -122,7 +280,14 @@ func (f *Function) Inspect() string {
 	params := []string{}
 
 	for _, p := range f.Parameters {
-		params = append(params, p.String())
+		if value, ok := f.Defaults[p.Value]; ok {
+			params = append(params, p.String()+" = "+value.String())
+		} else {
+			params = append(params, p.String())
+		}
+	}
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
 	}
 
 	out.WriteString("fn")
@@ -137,6 +302,9 @@ func (f *Function) Inspect() string {
 func (s *String) Type() ObjectType { return STRING_OBJ }
 func (s *String) Inspect() string  { return s.Value }
 
+func (m *Method) Type() ObjectType { return METHOD_OBJ }
+func (m *Method) Inspect() string  { return fmt.Sprintf("method %s of %s", m.Name, m.Receiver.Type()) }
+
 func (b *Builtin) Type() ObjectType { return BUILTIN_OBJ }
 func (b *Builtin) Inspect() string  { return "builtin function" }
 
@@ -169,6 +337,15 @@ func (i *Integer) HashKey() HashKey {
 	return HashKey{Type: i.Type(), Value: uint64(i.Value)}
 }
 
+// HashKey of a whole float is the key of the equal integer, so 2.0 and 2
+// find the same hash entry just like 2.0 == 2
+func (f *Float) HashKey() HashKey {
+	if f.Value == math.Trunc(f.Value) && f.Value >= math.MinInt64 && f.Value < math.MaxInt64 {
+		return (&Integer{Value: int64(f.Value)}).HashKey()
+	}
+	return HashKey{Type: f.Type(), Value: math.Float64bits(f.Value)}
+}
+
 func (s *String) HashKey() HashKey {
 	h := fnv.New64a()
 	h.Write([]byte(s.Value))
@@ -177,14 +354,19 @@ func (s *String) HashKey() HashKey {
 
 const (
 	INTEGER_OBJ      = "INTEGER"
+	FLOAT_OBJ        = "FLOAT"
 	BOOLEAN_OBJ      = "BOOLEAN"
 	NULL_OBJ         = "NULL"
 	RETURN_VALUE_OBJ = "RETURN_VALUE"
+	BREAK_OBJ        = "BREAK"
+	CONTINUE_OBJ     = "CONTINUE"
 	ERROR_OBJ        = "ERROR"
 	FUNCTION_OBJ     = "FUNCTION"
 	STRING_OBJ       = "STRING"
 	BUILTIN_OBJ      = "BUILTIN"
+	METHOD_OBJ       = "METHOD"
 	ARRAY_OBJ        = "ARRAY"
 	HASH_OBJ         = "HASH"
 	SET_OBJ          = "SET"
+	RANGE_OBJ        = "RANGE"
 )
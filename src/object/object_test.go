@@ -20,3 +20,129 @@ func TestStringHashKey(t *testing.T) {
 		t.Errorf("Stings with different content have same hash keys")
 	}
 }
+
+func TestFloatHashKey(t *testing.T) {
+	if (&Float{Value: 2.5}).HashKey() != (&Float{Value: 2.5}).HashKey() {
+		t.Errorf("Floats with same value have different hash keys")
+	}
+
+	if (&Float{Value: 2.5}).HashKey() == (&Float{Value: 3.5}).HashKey() {
+		t.Errorf("Floats with different values have same hash keys")
+	}
+
+	if (&Float{Value: 2}).HashKey() != (&Integer{Value: 2}).HashKey() {
+		t.Errorf("Whole float and equal integer have different hash keys")
+	}
+}
+
+func TestFloatInspect(t *testing.T) {
+	tests := []struct {
+		value    float64
+		expected string
+	}{
+		{3, "3.0"},
+		{3.5, "3.5"},
+		{-0.25, "-0.25"},
+		{1e-9, "1e-09"},
+	}
+
+	for _, tt := range tests {
+		if got := (&Float{Value: tt.value}).Inspect(); got != tt.expected {
+			t.Errorf("Inspect wrong. expected=%q, got=%q", tt.expected, got)
+		}
+	}
+}
+
+func TestEnvironmentAssign(t *testing.T) {
+	outer := NewEnvironment()
+	outer.Set("count", &Integer{Value: 1})
+	inner := NewEnclosedEnvironment(outer)
+
+	if !inner.Assign("count", &Integer{Value: 2}) {
+		t.Fatalf("Assign did not find count in the outer scope")
+	}
+
+	val, _ := outer.Get("count")
+	if val.(*Integer).Value != 2 {
+		t.Errorf("outer count not updated. got=%d", val.(*Integer).Value)
+	}
+	if _, ok := inner.store["count"]; ok {
+		t.Errorf("Assign declared count in the inner scope")
+	}
+
+	if inner.Assign("missing", &Integer{Value: 3}) {
+		t.Errorf("Assign reported success for an undeclared name")
+	}
+	if _, ok := inner.Get("missing"); ok {
+		t.Errorf("Assign declared an undeclared name")
+	}
+}
+
+func TestSortedPairs(t *testing.T) {
+	keys := []Object{
+		&String{Value: "b"},
+		&Boolean{Value: true},
+		&Integer{Value: 10},
+		&String{Value: "a"},
+		&Float{Value: 2.5},
+		&Boolean{Value: false},
+		&Integer{Value: -1},
+	}
+
+	hash := &Hash{Pairs: map[HashKey]HashPair{}}
+	for _, key := range keys {
+		hash.Pairs[key.(Hashable).HashKey()] = HashPair{Key: key, Value: key}
+	}
+
+	expected := []string{"-1", "2.5", "10", "false", "true", "a", "b"}
+
+	pairs := hash.SortedPairs()
+	if len(pairs) != len(expected) {
+		t.Fatalf("wrong number of pairs. want=%d, got=%d", len(expected), len(pairs))
+	}
+	for i, want := range expected {
+		if pairs[i].Key.Inspect() != want {
+			t.Errorf("pairs[%d] wrong. want=%s, got=%s", i, want, pairs[i].Key.Inspect())
+		}
+	}
+}
+
+func TestRange(t *testing.T) {
+	tests := []struct {
+		r        *Range
+		expected []int64
+	}{
+		{&Range{From: 1, To: 5, Step: 1, Inclusive: true}, []int64{1, 2, 3, 4, 5}},
+		{&Range{From: 1, To: 5, Step: 1}, []int64{1, 2, 3, 4}},
+		{&Range{From: 0, To: 10, Step: 3, Inclusive: true}, []int64{0, 3, 6, 9}},
+		{&Range{From: 0, To: 9, Step: 3}, []int64{0, 3, 6}},
+		{&Range{From: 5, To: 1, Step: -2, Inclusive: true}, []int64{5, 3, 1}},
+		{&Range{From: 5, To: 1, Step: 1, Inclusive: true}, []int64{}},
+		{&Range{From: 3, To: 3, Step: 1}, []int64{}},
+		{&Range{From: 3, To: 3, Step: 1, Inclusive: true}, []int64{3}},
+	}
+
+	for _, tt := range tests {
+		if tt.r.Len() != int64(len(tt.expected)) {
+			t.Errorf("%s: wrong Len. want=%d, got=%d", tt.r.Inspect(), len(tt.expected), tt.r.Len())
+			continue
+		}
+		for i, want := range tt.expected {
+			if got := tt.r.At(int64(i)); got != want {
+				t.Errorf("%s: At(%d) wrong. want=%d, got=%d", tt.r.Inspect(), i, want, got)
+			}
+			if !tt.r.Contains(want) {
+				t.Errorf("%s: does not contain %d", tt.r.Inspect(), want)
+			}
+		}
+		for _, n := range []int64{-1, 2, 7, 11} {
+			found := false
+			for _, want := range tt.expected {
+				found = found || want == n
+			}
+			if tt.r.Contains(n) != found {
+				t.Errorf("%s: Contains(%d) wrong. want=%t", tt.r.Inspect(), n, found)
+			}
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/psidh/Ganges/src/token"
+)
+
+// ParseError is a problem found while lexing or parsing a program
+type ParseError struct {
+	Pos      token.Position
+	Expected []token.TokenType // the token types that would have been valid, if known
+	Found    token.Token       // the token that was there instead, zero for lexer errors
+	Message  string
+}
+
+func (e ParseError) String() string {
+	return fmt.Sprintf("%s: %s", e.Pos, e.Message)
+}
+
+// ParseErrors returns the lexer's errors followed by the parser's own
+func (p *Parser) ParseErrors() []ParseError {
+	errors := []ParseError{}
+	for _, d := range p.l.Diagnostics() {
+		msg := d.Message
+		if d.Suggestion != "" {
+			msg = fmt.Sprintf("%s (%s)", msg, d.Suggestion)
+		}
+		errors = append(errors, ParseError{Pos: d.Pos, Message: msg})
+	}
+	return append(errors, p.errors...)
+}
+
+// Errors returns ParseErrors formatted as "file:line:col: message"
+func (p *Parser) Errors() []string {
+	errors := []string{}
+	for _, e := range p.ParseErrors() {
+		errors = append(errors, e.String())
+	}
+	return errors
+}
+
+// addError records msg about the token found, which wasn't one of the
+// expected types. Only the first error of a statement is kept, the rest
+// are usually just the parser tripping over the same mistake.
+func (p *Parser) addError(found token.Token, msg string, expected ...token.TokenType) {
+	if p.recovering {
+		return
+	}
+	p.recovering = true
+	p.errors = append(p.errors, ParseError{
+		Pos:      found.Pos,
+		Expected: expected,
+		Found:    found,
+		Message:  msg,
+	})
+}
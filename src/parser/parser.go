@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 	"strconv"
 
@@ -13,9 +14,15 @@ type Parser struct {
 	l              *lexer.Lexer
 	currToken      token.Token
 	peekToken      token.Token
-	errors         []string
+	ahead          []token.Token // tokens read past peekToken, see peekAt
+	errors         []ParseError
 	prefixParseFns map[token.TokenType]prefixParseFn
 	infixParseFns  map[token.TokenType]infixParseFn
+	loopDepth      int  // how many loop bodies enclose the current token
+	recovering     bool // the current statement has an error, see synchronize
+	braceDepth     int  // how many { are open, counting currToken
+	inPattern      bool // ...rest is allowed
+	inGuard        bool // parsing a milana guard, where ) => ends the guard
 }
 
 type (
@@ -24,33 +31,55 @@ type (
 )
 
 var precedences = map[token.TokenType]int{
-	token.EQ:       EQUALS,
-	token.NOT_EQ:   EQUALS,
-	token.LT:       LESSGREATER,
-	token.GT:       LESSGREATER,
-	token.PLUS:     SUM,
-	token.MINUS:    SUM,
-	token.SLASH:    PRODUCT,
-	token.ASTERISK: PRODUCT,
-	token.LPAREN:   CALL,
-	token.LBRACKET: INDEX,
+	token.ASSIGN:          ASSIGN,
+	token.PLUS_ASSIGN:     ASSIGN,
+	token.MINUS_ASSIGN:    ASSIGN,
+	token.ASTERISK_ASSIGN: ASSIGN,
+	token.SLASH_ASSIGN:    ASSIGN,
+	token.PERCENT_ASSIGN:  ASSIGN,
+	token.INCREMENT:       POSTFIX,
+	token.DECREMENT:       POSTFIX,
+	token.OR:              LOGICAL_OR,
+	token.AND:             LOGICAL_AND,
+	token.EQ:              EQUALS,
+	token.NOT_EQ:          EQUALS,
+	token.LT:              LESSGREATER,
+	token.GT:              LESSGREATER,
+	token.LT_EQ:           LESSGREATER,
+	token.GT_EQ:           LESSGREATER,
+	token.RANGE:           RANGE,
+	token.RANGE_LT:        RANGE,
+	token.PLUS:            SUM,
+	token.MINUS:           SUM,
+	token.SLASH:           PRODUCT,
+	token.ASTERISK:        PRODUCT,
+	token.PERCENT:         PRODUCT,
+	token.LPAREN:          CALL,
+	token.LBRACKET:        INDEX,
+	token.DOT:             INDEX,
 }
 
 // implementation of Parser on the Lexer
 func New(l *lexer.Lexer) *Parser {
-	p := &Parser{l: l, errors: []string{}}
+	p := &Parser{l: l, errors: []ParseError{}}
 
 	p.prefixParseFns = make(map[token.TokenType]prefixParseFn)
 	p.registerPrefix(token.IDENT, p.parseIdentifier)
 	p.registerPrefix(token.INT, p.parseIntegerLiteral)
+	p.registerPrefix(token.FLOAT, p.parseFloatLiteral)
 	p.registerPrefix(token.BANG, p.parsePrefixExpression)
 	p.registerPrefix(token.MINUS, p.parsePrefixExpression)
+	p.registerPrefix(token.INCREMENT, p.parsePrefixUpdateExpression)
+	p.registerPrefix(token.DECREMENT, p.parsePrefixUpdateExpression)
 	p.registerPrefix(token.SATYA, p.parseBoolean)
 	p.registerPrefix(token.ASATYA, p.parseBoolean)
 	p.registerPrefix(token.LPAREN, p.parseGroupedExpression)
 	p.registerPrefix(token.YADI, p.parseIfExpression)
+	p.registerPrefix(token.MILANA, p.parseMatchExpression)
+	p.registerPrefix(token.ELLIPSIS, p.parseRestElement)
 	p.registerPrefix(token.KRIYA, p.parseFunctionLiteral)
 	p.registerPrefix(token.VAKYA, p.parseStringLiteral)
+	p.registerPrefix(token.VAKYA_HEAD, p.parseInterpolatedString)
 	p.registerPrefix(token.LBRACKET, p.parseArrayLiteral)
 	p.registerPrefix(token.LBRACE, p.parseHashLiteral)
 	p.infixParseFns = make(map[token.TokenType]infixParseFn)
@@ -58,29 +87,58 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(token.MINUS, p.parseInfixExpression)
 	p.registerInfix(token.SLASH, p.parseInfixExpression)
 	p.registerInfix(token.ASTERISK, p.parseInfixExpression)
+	p.registerInfix(token.PERCENT, p.parseInfixExpression)
 	p.registerInfix(token.EQ, p.parseInfixExpression)
 	p.registerInfix(token.NOT_EQ, p.parseInfixExpression)
 	p.registerInfix(token.LT, p.parseInfixExpression)
 	p.registerInfix(token.GT, p.parseInfixExpression)
+	p.registerInfix(token.LT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.GT_EQ, p.parseInfixExpression)
+	p.registerInfix(token.AND, p.parseInfixExpression)
+	p.registerInfix(token.OR, p.parseInfixExpression)
+	p.registerInfix(token.ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PLUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.MINUS_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.ASTERISK_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.SLASH_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.PERCENT_ASSIGN, p.parseAssignExpression)
+	p.registerInfix(token.INCREMENT, p.parsePostfixUpdateExpression)
+	p.registerInfix(token.DECREMENT, p.parsePostfixUpdateExpression)
+	p.registerInfix(token.RANGE, p.parseRangeExpression)
+	p.registerInfix(token.RANGE_LT, p.parseRangeExpression)
 	p.registerInfix(token.LPAREN, p.parseCallExpression)
 	p.registerInfix(token.LBRACKET, p.parseIndexExpression)
+	p.registerInfix(token.DOT, p.parseMemberExpression)
 	p.nextToken()
 	p.nextToken()
 	return p
 }
 
-func (p *Parser) Errors() []string {
-	return p.errors
-}
-
 func (p *Parser) peekError(t token.TokenType) {
+	// the lexer has already explained what is wrong with an ILLEGAL token
+	if p.peekTokenIs(token.ILLEGAL) {
+		p.recovering = true
+		return
+	}
 	msg := fmt.Sprintf("Expected next token to be %s, instead got %s", t, p.peekToken.Type)
-	p.errors = append(p.errors, msg)
+	p.addError(p.peekToken, msg, t)
 }
 
 func (p *Parser) nextToken() {
 	p.currToken = p.peekToken
-	p.peekToken = p.l.NextToken()
+	if len(p.ahead) > 0 {
+		p.peekToken = p.ahead[0]
+		p.ahead = p.ahead[1:]
+	} else {
+		p.peekToken = p.l.NextToken()
+	}
+
+	switch p.currToken.Type {
+	case token.LBRACE:
+		p.braceDepth++
+	case token.RBRACE:
+		p.braceDepth--
+	}
 }
 
 func (p *Parser) ParseProgram() *ast.Program {
@@ -89,15 +147,69 @@ func (p *Parser) ParseProgram() *ast.Program {
 	program.Statements = []ast.Statement{}
 
 	for p.currToken.Type != token.EOF {
-		stmt := p.parseStatement()
+		stmt := p.parseNextStatement()
 		if stmt != nil {
 			program.Statements = append(program.Statements, stmt)
 		}
 		p.nextToken()
 	}
+
+	for _, c := range p.l.Comments() {
+		program.Comments = append(program.Comments, &ast.Comment{Token: c, Text: c.Literal})
+	}
 	return program
 }
 
+// parseNextStatement parses a statement. If it has an error it is dropped
+// and the rest of it skipped, so the next statement parses cleanly.
+func (p *Parser) parseNextStatement() ast.Statement {
+	// a block inside a statement that is already broken is skipped by
+	// the enclosing statement, not here
+	recovering := p.recovering
+
+	depth := p.braceDepth
+	if p.currTokenIs(token.LBRACE) {
+		depth--
+	}
+
+	stmt := p.parseStatement()
+	if !p.recovering || recovering {
+		return stmt
+	}
+
+	p.synchronize(depth)
+	p.recovering = false
+	return nil
+}
+
+// synchronize skips tokens up to the end of a broken statement that began
+// with depth braces open. Outside of any braces the statement opened, it
+// ends at a ; or before a }, the end of input or a keyword that starts a
+// statement.
+func (p *Parser) synchronize(depth int) {
+	for !p.currTokenIs(token.EOF) {
+		if p.braceDepth <= depth {
+			if p.currTokenIs(token.SEMICOLON) {
+				return
+			}
+			if p.peekTokenIs(token.EOF) || p.peekTokenIs(token.RBRACE) || isStatementKeyword(p.peekToken.Type) {
+				return
+			}
+		}
+		p.nextToken()
+	}
+}
+
+// isStatementKeyword reports whether t can only start a statement, so it
+// ends whatever statement came before it
+func isStatementKeyword(t token.TokenType) bool {
+	switch t {
+	case token.RAMA, token.DAAN, token.CHAKRA, token.PRATYEK, token.VIRAM, token.AGLA:
+		return true
+	}
+	return false
+}
+
 func (p *Parser) parseStatement() ast.Statement {
 	switch p.currToken.Type {
 	case token.RAMA:
@@ -106,6 +218,10 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseReturnStatement()
 	case token.CHAKRA:
 		return p.parseChakraStatement()
+	case token.PRATYEK:
+		return p.parsePratyekStatement()
+	case token.VIRAM, token.AGLA:
+		return p.parseLoopControlStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -146,12 +262,19 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 func (p *Parser) parseRamaStatement() *ast.RamaStatement {
 	stmt := &ast.RamaStatement{Token: p.currToken}
 
-	if !p.expectPeek(token.IDENT) {
-		return nil
+	if p.peekTokenIs(token.LBRACKET) || p.peekTokenIs(token.LBRACE) {
+		p.nextToken()
+		stmt.Pattern = p.parsePattern()
+		if stmt.Pattern == nil {
+			return nil
+		}
+	} else {
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
 	}
 
-	stmt.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
-
 	if !p.expectPeek(token.ASSIGN) {
 		return nil
 	}
@@ -160,6 +283,11 @@ func (p *Parser) parseRamaStatement() *ast.RamaStatement {
 
 	stmt.Value = p.parseExpression(LOWEST)
 
+	// name the function for error messages
+	if fn, ok := stmt.Value.(*ast.FunctionLiteral); ok && stmt.Name != nil {
+		fn.Name = stmt.Name.Value
+	}
+
 	if p.peekTokenIs(token.SEMICOLON) {
 		p.nextToken()
 	}
@@ -169,14 +297,17 @@ func (p *Parser) parseRamaStatement() *ast.RamaStatement {
 func (p *Parser) parseReturnStatement() *ast.ReturnStatement {
 	stmt := &ast.ReturnStatement{Token: p.currToken}
 
-	p.nextToken()
-
-	stmt.ReturnValue = p.parseExpression(LOWEST)
-
-	for !p.currTokenIs(token.SEMICOLON) {
+	// daan on its own gives back nothing
+	bare := p.peekTokenIs(token.SEMICOLON) || p.peekTokenIs(token.RBRACE) ||
+		p.peekTokenIs(token.EOF) || isStatementKeyword(p.peekToken.Type)
+	if !bare {
 		p.nextToken()
+		stmt.ReturnValue = p.parseExpression(LOWEST)
 	}
 
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
 	return stmt
 }
 
@@ -214,11 +345,33 @@ func (p *Parser) parseIdentifier() ast.Expression {
 func (p *Parser) parseIntegerLiteral() ast.Expression {
 	lit := &ast.IntegerLiteral{Token: p.currToken}
 
+	// base 0 understands the 0x, 0b and 0o prefixes and _ separators
 	value, err := strconv.ParseInt(p.currToken.Literal, 0, 64)
 
+	if errors.Is(err, strconv.ErrRange) {
+		msg := fmt.Sprintf("integer literal %s overflows int64", p.currToken.Literal)
+		p.addError(p.currToken, msg)
+		return nil
+	}
+
 	if err != nil {
 		msg := fmt.Sprintf("could not parse %q as integer", p.currToken.Literal)
-		p.errors = append(p.errors, msg)
+		p.addError(p.currToken, msg)
+		return nil
+	}
+
+	lit.Value = value
+	return lit
+}
+
+func (p *Parser) parseFloatLiteral() ast.Expression {
+	lit := &ast.FloatLiteral{Token: p.currToken}
+
+	value, err := strconv.ParseFloat(p.currToken.Literal, 64)
+
+	if err != nil {
+		msg := fmt.Sprintf("could not parse %q as float", p.currToken.Literal)
+		p.addError(p.currToken, msg)
 		return nil
 	}
 
@@ -227,8 +380,12 @@ func (p *Parser) parseIntegerLiteral() ast.Expression {
 }
 
 func (p *Parser) noPrefixParseFnError(t token.TokenType) {
+	if t == token.ILLEGAL {
+		p.recovering = true
+		return
+	}
 	msg := fmt.Sprintf("no prefix parse function for %s found", t)
-	p.errors = append(p.errors, msg)
+	p.addError(p.currToken, msg)
 }
 
 func (p *Parser) parsePrefixExpression() ast.Expression {
@@ -265,6 +422,85 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expression
 }
 
+// parseAssignExpression parses target = value and the compound forms like
+// target += value. Assignment is right associative, so a = b = 1 sets both.
+func (p *Parser) parseAssignExpression(target ast.Expression) ast.Expression {
+	expression := &ast.AssignExpression{
+		Token:    p.currToken,
+		Target:   target,
+		Operator: p.currToken.Literal,
+	}
+
+	if !p.checkAssignTarget(expression.Token, target) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Value = p.parseExpression(LOWEST)
+	return expression
+}
+
+func (p *Parser) parseRangeExpression(from ast.Expression) ast.Expression {
+	expression := &ast.RangeExpression{
+		Token:     p.currToken,
+		From:      from,
+		Inclusive: p.currTokenIs(token.RANGE),
+	}
+
+	p.nextToken()
+	expression.To = p.parseExpression(RANGE)
+
+	if p.peekTokenIs(token.KADAM) {
+		p.nextToken()
+		p.nextToken()
+		expression.Step = p.parseExpression(RANGE)
+	}
+
+	return expression
+}
+
+func (p *Parser) parsePrefixUpdateExpression() ast.Expression {
+	expression := &ast.UpdateExpression{
+		Token:    p.currToken,
+		Operator: p.currToken.Literal,
+		Prefix:   true,
+	}
+
+	p.nextToken()
+	expression.Target = p.parseExpression(PREFIX)
+
+	if !p.checkAssignTarget(expression.Token, expression.Target) {
+		return nil
+	}
+	return expression
+}
+
+func (p *Parser) parsePostfixUpdateExpression(target ast.Expression) ast.Expression {
+	if !p.checkAssignTarget(p.currToken, target) {
+		return nil
+	}
+
+	return &ast.UpdateExpression{
+		Token:    p.currToken,
+		Operator: p.currToken.Literal,
+		Target:   target,
+	}
+}
+
+// checkAssignTarget reports a target the operator tok can't assign to.
+// Names and elements like xs[i], hash["key"] or hash.key can be assigned to.
+func (p *Parser) checkAssignTarget(tok token.Token, target ast.Expression) bool {
+	switch target.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.MemberExpression:
+		return true
+	case nil:
+		return false
+	}
+
+	p.addError(tok, fmt.Sprintf("cannot assign to %s", target))
+	return false
+}
+
 func (p *Parser) currPrecedence() int {
 	if p, ok := precedences[p.currToken.Type]; ok {
 		return p
@@ -276,7 +512,44 @@ func (p *Parser) parseBoolean() ast.Expression {
 	return &ast.Boolean{Token: p.currToken, Value: p.currTokenIs(token.SATYA)}
 }
 
+// peekAt returns the token n places after peekToken without consuming
+// anything, so peekAt(0) is peekToken itself
+func (p *Parser) peekAt(n int) token.Token {
+	if n == 0 {
+		return p.peekToken
+	}
+	for len(p.ahead) < n {
+		p.ahead = append(p.ahead, p.l.NextToken())
+	}
+	return p.ahead[n-1]
+}
+
+// arrowAhead reports whether the ( at currToken starts the parameters of
+// an arrow function, that is whether its ) is followed by =>
+func (p *Parser) arrowAhead() bool {
+	depth := 0
+	for i := 0; ; i++ {
+		switch p.peekAt(i).Type {
+		case token.LPAREN:
+			depth++
+		case token.RPAREN:
+			if depth == 0 {
+				return p.peekAt(i+1).Type == token.ARROW
+			}
+			depth--
+		case token.EOF:
+			return false
+		}
+	}
+}
+
 func (p *Parser) parseGroupedExpression() ast.Expression {
+	// in a pattern like (1) => or a guard like yadi (x > 1) => the arrow
+	// belongs to the milana arm
+	if !p.inPattern && !p.inGuard && p.arrowAhead() {
+		return p.parseArrowFunction()
+	}
+
 	p.nextToken()
 
 	exp := p.parseExpression(LOWEST)
@@ -291,36 +564,58 @@ func (p *Parser) parseGroupedExpression() ast.Expression {
 func (p *Parser) parseIfExpression() ast.Expression {
 	expression := &ast.IfExpression{Token: p.currToken}
 
-	if !p.expectPeek(token.LPAREN) {
+	expression.Condition, expression.Consequence = p.parseIfBranch()
+	if expression.Consequence == nil {
 		return nil
 	}
 
-	p.nextToken()
-	expression.Condition = p.parseExpression(LOWEST)
-
-	if !p.expectPeek(token.RPAREN) {
-		return nil
-	}
-
-	if !p.expectPeek(token.LBRACE) {
-		return nil
-	}
-
-	expression.Consequence = p.parseBlockStatement()
-
-	if p.peekTokenIs(token.ANYATHA) {
+	for p.peekTokenIs(token.ANYATHA) {
 		p.nextToken()
+		elseToken := p.currToken
+
+		if p.peekTokenIs(token.YADI) {
+			p.nextToken()
+			branch := &ast.ElseIf{Token: elseToken}
+			branch.Condition, branch.Consequence = p.parseIfBranch()
+			if branch.Consequence == nil {
+				return nil
+			}
+			expression.ElseIfs = append(expression.ElseIfs, branch)
+			continue
+		}
 
 		if !p.expectPeek(token.LBRACE) {
 			return nil
 		}
 
 		expression.Alternative = p.parseBlockStatement()
+		break
 	}
 
 	return expression
 }
 
+// parseIfBranch parses the (condition) { ... } after a yadi token. The
+// block is nil when either part is missing.
+func (p *Parser) parseIfBranch() (ast.Expression, *ast.BlockStatement) {
+	if !p.expectPeek(token.LPAREN) {
+		return nil, nil
+	}
+
+	p.nextToken()
+	condition := p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil, nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil, nil
+	}
+
+	return condition, p.parseBlockStatement()
+}
+
 func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	block := &ast.BlockStatement{Token: p.currToken}
 	block.Statements = []ast.Statement{}
@@ -328,13 +623,19 @@ func (p *Parser) parseBlockStatement() *ast.BlockStatement {
 	p.nextToken()
 
 	for !p.currTokenIs(token.RBRACE) && !p.currTokenIs(token.EOF) {
-		stmt := p.parseStatement()
+		stmt := p.parseNextStatement()
 		if stmt != nil {
 			block.Statements = append(block.Statements, stmt)
 		}
 		p.nextToken()
 	}
 
+	if p.currTokenIs(token.RBRACE) {
+		block.Closing = p.currToken
+	} else {
+		p.addError(p.currToken, fmt.Sprintf("Expected next token to be }, instead got %s", p.currToken.Type), token.RBRACE)
+	}
+
 	return block
 }
 
@@ -343,45 +644,122 @@ func (p *Parser) parseFunctionLiteral() ast.Expression {
 	if !p.expectPeek(token.LPAREN) {
 		return nil
 	}
-	lit.Parameters = p.parseFunctionParameters()
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
 	if !p.expectPeek(token.LBRACE) {
 		return nil
 	}
-	lit.Body = p.parseBlockStatement()
+
+	lit.Body = p.parseFunctionBody(p.parseBlockStatement)
 	return lit
 
 }
 
-func (p *Parser) parseFunctionParameters() []*ast.Identifier {
-	identifiers := []*ast.Identifier{}
+// parseFunctionBody parses the body of a function with parse. The body
+// stands on its own: a loop around the function doesn't make viram legal
+// in it, and a milana guard around it doesn't rule out arrow functions.
+func (p *Parser) parseFunctionBody(parse func() *ast.BlockStatement) *ast.BlockStatement {
+	loopDepth, inGuard := p.loopDepth, p.inGuard
+	p.loopDepth, p.inGuard = 0, false
+	body := parse()
+	p.loopDepth, p.inGuard = loopDepth, inGuard
+	return body
+}
 
-	if p.peekTokenIs(token.RPAREN) {
+// parseArrowFunction parses (params) => body into the same FunctionLiteral
+// as kriya. A body that isn't a block is returned as if by daan.
+func (p *Parser) parseArrowFunction() ast.Expression {
+	lit := &ast.FunctionLiteral{Token: p.currToken}
+	if !p.parseFunctionParameters(lit) {
+		return nil
+	}
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+
+	lit.Body = p.parseFunctionBody(p.parseArrowBody)
+	if lit.Body == nil {
+		return nil
+	}
+	return lit
+}
+
+func (p *Parser) parseArrowBody() *ast.BlockStatement {
+	if p.peekTokenIs(token.LBRACE) {
 		p.nextToken()
-		return identifiers
+		return p.parseBlockStatement()
 	}
 
+	arrow := p.currToken
 	p.nextToken()
+	value := p.parseExpression(LOWEST)
+	if value == nil {
+		return nil
+	}
 
-	ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	return &ast.BlockStatement{
+		Token:      arrow,
+		Statements: []ast.Statement{&ast.ReturnStatement{Token: arrow, ReturnValue: value}},
+	}
+}
 
-	identifiers = append(identifiers, ident)
+// parseFunctionParameters parses (a, b = 10, ...rest) into lit. Parameters
+// with a default come after the ones without, and ...rest comes last.
+func (p *Parser) parseFunctionParameters(lit *ast.FunctionLiteral) bool {
+	lit.Parameters = []*ast.Identifier{}
 
-	for p.peekTokenIs(token.COMMA) {
-		p.nextToken()
+	for !p.peekTokenIs(token.RPAREN) {
+		if lit.Rest != nil {
+			p.addError(lit.Rest.Token, fmt.Sprintf("...%s must be the last parameter", lit.Rest.Value))
+			return false
+		}
+
+		if p.peekTokenIs(token.ELLIPSIS) {
+			p.nextToken()
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+			lit.Rest = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+		} else {
+			if !p.expectPeek(token.IDENT) {
+				return false
+			}
+			ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+			lit.Parameters = append(lit.Parameters, ident)
+
+			if p.peekTokenIs(token.ASSIGN) {
+				p.nextToken()
+				p.nextToken()
+				if lit.Defaults == nil {
+					lit.Defaults = map[string]ast.Expression{}
+				}
+				lit.Defaults[ident.Value] = p.parseExpression(LOWEST)
+			} else if len(lit.Defaults) > 0 {
+				p.addError(ident.Token, fmt.Sprintf("parameter %s needs a default value, it follows one that has one", ident.Value))
+				return false
+			}
+		}
+
+		if !p.peekTokenIs(token.COMMA) {
+			return p.expectPeek(token.RPAREN)
+		}
 		p.nextToken()
-		ident := &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
-		identifiers = append(identifiers, ident)
 	}
 
-	if !p.expectPeek(token.RPAREN) {
-		return nil
-	}
-	return identifiers
+	p.nextToken()
+	return true
 }
 
 func (p *Parser) parseCallExpression(function ast.Expression) ast.Expression {
 	exp := &ast.CallExpression{Token: p.currToken, Function: function}
+
+	// the ) of an argument list never ends a milana guard
+	inGuard := p.inGuard
+	p.inGuard = false
 	exp.Arguments = p.parseCallArguments()
+	p.inGuard = inGuard
+	exp.Closing = p.currToken
 	return exp
 }
 
@@ -413,10 +791,47 @@ func (p *Parser) parseStringLiteral() ast.Expression {
 	return &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal}
 }
 
+func (p *Parser) parseInterpolatedString() ast.Expression {
+	str := &ast.InterpolatedString{Token: p.currToken}
+	str.Parts = p.appendStringPart(str.Parts)
+
+	for {
+		p.nextToken()
+
+		if p.currTokenIs(token.VAKYA_MIDDLE) || p.currTokenIs(token.VAKYA_TAIL) {
+			p.addError(p.currToken, "empty ${} in string")
+			return nil
+		}
+		str.Parts = append(str.Parts, p.parseExpression(LOWEST))
+
+		if p.peekTokenIs(token.VAKYA_MIDDLE) {
+			p.nextToken()
+			str.Parts = p.appendStringPart(str.Parts)
+			continue
+		}
+
+		if !p.expectPeek(token.VAKYA_TAIL) {
+			return nil
+		}
+		str.Parts = p.appendStringPart(str.Parts)
+		str.Closing = p.currToken
+		return str
+	}
+}
+
+// appendStringPart adds the text of the current string piece, if any
+func (p *Parser) appendStringPart(parts []ast.Expression) []ast.Expression {
+	if p.currToken.Literal == "" {
+		return parts
+	}
+	return append(parts, &ast.StringLiteral{Token: p.currToken, Value: p.currToken.Literal})
+}
+
 func (p *Parser) parseArrayLiteral() ast.Expression {
 	array := &ast.ArrayLiteral{Token: p.currToken}
 
 	array.Elements = p.parseExpressionList(token.RBRACKET)
+	array.Closing = p.currToken
 
 	return array
 }
@@ -446,15 +861,52 @@ func (p *Parser) parseExpressionList(end token.TokenType) []ast.Expression {
 	return list
 }
 
+func (p *Parser) parseMemberExpression(object ast.Expression) ast.Expression {
+	exp := &ast.MemberExpression{Token: p.currToken, Object: object}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	exp.Property = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+
+	return exp
+}
+
+// parseIndexExpression parses left[index], or the slice left[low:high] as
+// soon as it meets a colon
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
-	exp := &ast.IndexExpression{Token: p.currToken, Left: left}
+	tok := p.currToken
 
-	p.nextToken()
-	exp.Index = p.parseExpression(LOWEST)
+	var index ast.Expression
+	if !p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		index = p.parseExpression(LOWEST)
+	}
+
+	if p.peekTokenIs(token.COLON) {
+		p.nextToken()
+		return p.parseSliceExpression(tok, left, index)
+	}
+
+	if !p.expectPeek(token.RBRACKET) {
+		return nil
+	}
+
+	return &ast.IndexExpression{Token: tok, Left: left, Index: index, Closing: p.currToken}
+}
+
+func (p *Parser) parseSliceExpression(tok token.Token, left, low ast.Expression) ast.Expression {
+	exp := &ast.SliceExpression{Token: tok, Left: left, Low: low}
+
+	if !p.peekTokenIs(token.RBRACKET) {
+		p.nextToken()
+		exp.High = p.parseExpression(LOWEST)
+	}
 
 	if !p.expectPeek(token.RBRACKET) {
 		return nil
 	}
+	exp.Closing = p.currToken
 
 	return exp
 
@@ -479,9 +931,168 @@ func (p *Parser) parseHashLiteral() ast.Expression {
 	if !p.expectPeek(token.RBRACE) {
 		return nil
 	}
+	hash.Closing = p.currToken
 	return hash
 }
 
+func (p *Parser) parseMatchExpression() ast.Expression {
+	expression := &ast.MatchExpression{Token: p.currToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	p.nextToken()
+	expression.Subject = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	for !p.peekTokenIs(token.RBRACE) {
+		p.nextToken()
+		arm := p.parseMatchArm()
+		if arm == nil {
+			return nil
+		}
+		expression.Arms = append(expression.Arms, arm)
+
+		if !p.peekTokenIs(token.RBRACE) && !p.expectPeek(token.COMMA) {
+			return nil
+		}
+	}
+
+	if !p.expectPeek(token.RBRACE) {
+		return nil
+	}
+	expression.Closing = p.currToken
+	return expression
+}
+
+func (p *Parser) parseMatchArm() *ast.MatchArm {
+	arm := &ast.MatchArm{}
+
+	arm.Pattern = p.parsePattern()
+	if arm.Pattern == nil {
+		return nil
+	}
+
+	if p.peekTokenIs(token.YADI) {
+		p.nextToken()
+		p.nextToken()
+		inGuard := p.inGuard
+		p.inGuard = true
+		arm.Guard = p.parseExpression(LOWEST)
+		p.inGuard = inGuard
+	}
+
+	if !p.expectPeek(token.ARROW) {
+		return nil
+	}
+	arm.Token = p.currToken
+
+	p.nextToken()
+	arm.Body = p.parseExpression(LOWEST)
+	if arm.Body == nil {
+		return nil
+	}
+	return arm
+}
+
+// parsePattern parses the pattern starting at currToken. It stops before
+// any = so it can be used for the left side of rama.
+func (p *Parser) parsePattern() ast.Expression {
+	p.inPattern = true
+	pattern := p.parseExpression(ASSIGN)
+	p.inPattern = false
+
+	if !p.checkPattern(pattern) {
+		return nil
+	}
+	return pattern
+}
+
+func (p *Parser) parseRestElement() ast.Expression {
+	rest := &ast.RestElement{Token: p.currToken}
+
+	if !p.inPattern {
+		p.addError(p.currToken, "... is only allowed in patterns")
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	rest.Name = &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal}
+	return rest
+}
+
+// checkPattern reports an expression that can't be used as a pattern.
+// Patterns are literals, names, and array or hash literals of patterns
+// where the hash keys are literals. An array pattern may end in ...rest.
+func (p *Parser) checkPattern(pattern ast.Expression) bool {
+	switch pattern := pattern.(type) {
+	case nil:
+		return false
+	case *ast.Identifier:
+		return true
+	case *ast.ArrayLiteral:
+		for i, element := range pattern.Elements {
+			if _, ok := element.(*ast.RestElement); ok {
+				if i == len(pattern.Elements)-1 {
+					continue
+				}
+				p.addError(patternToken(element), fmt.Sprintf("%s must be the last element", element))
+				return false
+			}
+			if !p.checkPattern(element) {
+				return false
+			}
+		}
+		return true
+	case *ast.HashLiteral:
+		for key, value := range pattern.Pairs {
+			if !isLiteral(key) {
+				p.addError(patternToken(key), fmt.Sprintf("hash pattern keys must be literals, got %s", key))
+				return false
+			}
+			if !p.checkPattern(value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if isLiteral(pattern) {
+		return true
+	}
+	p.addError(patternToken(pattern), fmt.Sprintf("invalid pattern %s", pattern))
+	return false
+}
+
+// isLiteral reports whether e is a constant like 1, -2.5, "a" or satya
+func isLiteral(e ast.Expression) bool {
+	switch e := e.(type) {
+	case *ast.IntegerLiteral, *ast.FloatLiteral, *ast.StringLiteral, *ast.Boolean:
+		return true
+	case *ast.PrefixExpression:
+		switch e.Right.(type) {
+		case *ast.IntegerLiteral, *ast.FloatLiteral:
+			return e.Operator == "-"
+		}
+	}
+	return false
+}
+
+// patternToken stands in for the token of a bad pattern in an error
+func patternToken(pattern ast.Expression) token.Token {
+	return token.Token{Literal: pattern.TokenLiteral(), Pos: pattern.Pos(), End: pattern.End()}
+}
+
 func (p *Parser) parseChakraStatement() ast.Statement {
 
 	stmt := &ast.ChakraStatement{Token: p.currToken}
@@ -502,19 +1113,94 @@ func (p *Parser) parseChakraStatement() ast.Statement {
 		return nil
 	}
 
+	p.loopDepth++
+	stmt.Body = p.parseBlockStatement()
+	p.loopDepth--
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+	return stmt
+}
+
+func (p *Parser) parsePratyekStatement() ast.Statement {
+	stmt := &ast.PratyekStatement{Token: p.currToken}
+
+	if !p.expectPeek(token.LPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.IDENT) {
+		return nil
+	}
+	stmt.Variables = append(stmt.Variables, &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal})
+
+	if p.peekTokenIs(token.COMMA) {
+		p.nextToken()
+		if !p.expectPeek(token.IDENT) {
+			return nil
+		}
+		stmt.Variables = append(stmt.Variables, &ast.Identifier{Token: p.currToken, Value: p.currToken.Literal})
+	}
+
+	if !p.expectPeek(token.COLON) {
+		return nil
+	}
+
+	p.nextToken()
+	stmt.Iterable = p.parseExpression(LOWEST)
+
+	if !p.expectPeek(token.RPAREN) {
+		return nil
+	}
+
+	if !p.expectPeek(token.LBRACE) {
+		return nil
+	}
+
+	p.loopDepth++
 	stmt.Body = p.parseBlockStatement()
+	p.loopDepth--
 
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
 	return stmt
 }
 
+// parseLoopControlStatement parses viram and agla, which only make sense
+// inside a loop body
+func (p *Parser) parseLoopControlStatement() ast.Statement {
+	tok := p.currToken
+
+	if p.peekTokenIs(token.SEMICOLON) {
+		p.nextToken()
+	}
+
+	if p.loopDepth == 0 {
+		p.addError(tok, fmt.Sprintf("%s outside of a loop", tok.Literal))
+		return nil
+	}
+
+	if tok.Type == token.VIRAM {
+		return &ast.BreakStatement{Token: tok}
+	}
+	return &ast.ContinueStatement{Token: tok}
+}
+
 const (
 	_ int = iota
 	LOWEST
+	ASSIGN
+	LOGICAL_OR
+	LOGICAL_AND
 	EQUALS
 	LESSGREATER
+	RANGE
 	SUM
 	PRODUCT
 	PREFIX
+	POSTFIX
 	CALL
 	INDEX
 )
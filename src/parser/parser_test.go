@@ -2,10 +2,12 @@ package parser
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/psidh/Ganges/src/ast"
 	"github.com/psidh/Ganges/src/lexer"
+	"github.com/psidh/Ganges/src/token"
 )
 
 func TestRamaStatements(t *testing.T) {
@@ -88,6 +90,20 @@ func checkParserErrors(t *testing.T, p *Parser) {
 	t.FailNow()
 }
 
+// testParseError checks that parsing input reports exactly the expected error
+func testParseError(t *testing.T, input string, expected string) bool {
+	l := lexer.New(input)
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) != 1 || errors[0] != expected {
+		t.Errorf("%q: wrong errors. expected=%q, got=%v", input, expected, errors)
+		return false
+	}
+	return true
+}
+
 func testRamaStatement(t *testing.T, s ast.Statement, name string) bool {
 	if s.TokenLiteral() != "rama" {
 		t.Errorf("s.TokenLiteral not 'rama'. got=%q", s.TokenLiteral())
@@ -186,6 +202,83 @@ func TestIntegerLiteralExpression(t *testing.T) {
 	}
 }
 
+func TestIntegerLiteralForms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"0xff", 255},
+		{"0b1010", 10},
+		{"0o755", 493},
+		{"1_000_000", 1000000},
+		{"0x7fff_ffff_ffff_ffff", 9223372036854775807},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.IntegerLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.IntegerLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %d. got=%d", tt.expected, literal.Value)
+		}
+		if literal.String() != tt.input {
+			t.Errorf("literal.String() not %q. got=%q", tt.input, literal.String())
+		}
+	}
+}
+
+func TestInvalidIntegerLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"rama x = 9223372036854775808;", "1:10: integer literal 9223372036854775808 overflows int64"},
+		{"rama x =\n  0xffff_ffff_ffff_ffff_f;", "2:3: integer literal 0xffff_ffff_ffff_ffff_f overflows int64"},
+		{"0b102", "1:1: invalid number 0b102 (binary literals use the digits 0 and 1)"},
+		{"1__0", "1:1: invalid number 1__0 (use _ only between digits)"},
+		{"0x", "1:1: invalid number 0x (hex literals use the digits 0-9 and a-f)"},
+		{"rama x = 010;", "1:10: invalid number 010 (remove the leading 0, or write octal as 0o...)"},
+	}
+
+	for _, tt := range tests {
+		testParseError(t, tt.input, tt.expected)
+	}
+}
+
+func TestFloatLiteralExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{"3.14;", 3.14},
+		{"1e-9;", 1e-9},
+		{"2.5E3;", 2500},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		literal, ok := stmt.Expression.(*ast.FloatLiteral)
+		if !ok {
+			t.Fatalf("exp not *ast.FloatLiteral. got=%T", stmt.Expression)
+		}
+		if literal.Value != tt.expected {
+			t.Errorf("literal.Value not %g. got=%g", tt.expected, literal.Value)
+		}
+	}
+}
+
 func TestParsingPrefixExpressions(t *testing.T) {
 	prefixTests := []struct {
 		input    string
@@ -348,6 +441,11 @@ func TestParsingInfixExpressions(t *testing.T) {
 		{"5 < 5;", 5, "<", 5},
 		{"5 == 5;", 5, "==", 5},
 		{"5 != 5;", 5, "!=", 5},
+		{"5 <= 5;", 5, "<=", 5},
+		{"5 >= 5;", 5, ">=", 5},
+		{"5 % 5;", 5, "%", 5},
+		{"satya && asatya", true, "&&", false},
+		{"satya || asatya", true, "||", false},
 		{"satya != asatya", true, "!=", false},
 		{"satya == asatya", true, "==", false},
 	}
@@ -506,6 +604,26 @@ func TestOperatorPrecedenceParsing(t *testing.T) {
 			"add(a * b[2], b[1], 2 * [1, 2][1])",
 			"add((a * (b[2])), (b[1]), (2 * ([1, 2][1])))",
 		},
+		{
+			"a + b % c * d",
+			"(a + ((b % c) * d))",
+		},
+		{
+			"a <= b == c >= d",
+			"((a <= b) == (c >= d))",
+		},
+		{
+			"a || b && c",
+			"(a || (b && c))",
+		},
+		{
+			"a && b || c && d",
+			"((a && b) || (c && d))",
+		},
+		{
+			"a == 1 && b < 2 || !c",
+			"(((a == 1) && (b < 2)) || (!c))",
+		},
 	}
 	for _, tt := range tests {
 		l := lexer.New(tt.input)
@@ -570,6 +688,44 @@ func TestIfExpression(t *testing.T) {
 	}
 }
 
+func TestElseIfChain(t *testing.T) {
+	input := `yadi (x < y) { x } anyatha yadi (x > y) { y } anyatha yadi (z) { z } anyatha { 0 }`
+
+	l := lexer.New(input)
+	p := New(l)
+
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Body does not contain %d statements. got=%d\n", 1, len(program.Statements))
+	}
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+
+	exp, ok := stmt.Expression.(*ast.IfExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.IfExpression. got=%T", stmt.Expression)
+	}
+
+	if len(exp.ElseIfs) != 2 {
+		t.Fatalf("exp.ElseIfs does not contain 2 branches. got=%d", len(exp.ElseIfs))
+	}
+	testInfixExpression(t, exp.ElseIfs[0].Condition, "x", ">", "y")
+	testIdentifier(t, exp.ElseIfs[1].Condition, "z")
+
+	if exp.Alternative == nil {
+		t.Fatalf("exp.Alternative is nil")
+	}
+
+	expected := "if(x < y) xelse if(x > y) yelse ifz zelse 0"
+	if exp.String() != expected {
+		t.Errorf("exp.String() wrong. expected=%q, got=%q", expected, exp.String())
+	}
+	if exp.End().Column != len(input)+1 {
+		t.Errorf("exp.End() wrong. got=%s", exp.End())
+	}
+}
+
 func TestFunctionLiteralParsing(t *testing.T) {
 	input := `kriya(x, y) { x + y;}`
 
@@ -876,3 +1032,831 @@ func TestChakraStatement(t *testing.T) {
 		return
 	}
 }
+
+func TestParserErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"rama x 5;", "main.ga:1:8: Expected next token to be =, instead got INT"},
+		{"rama x = 1;\nadd(1, 2 {", "main.ga:2:10: Expected next token to be ), instead got {"},
+		{"\n  ;", "main.ga:2:3: no prefix parse function for ; found"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.NewFile("main.ga", tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) == 0 {
+			t.Errorf("expected parser errors for %q, got none", tt.input)
+			continue
+		}
+		if errors[0] != tt.expected {
+			t.Errorf("wrong error. expected=%q, got=%q", tt.expected, errors[0])
+		}
+	}
+}
+
+func TestNodePositions(t *testing.T) {
+	input := "rama x = [1, 2];\nadd(x, 3 * 4);"
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	tests := []struct {
+		node                ast.Node
+		startLine, startCol int
+		endLine, endCol     int
+	}{
+		{program.Statements[0], 1, 1, 1, 16},
+		{program.Statements[0].(*ast.RamaStatement).Value, 1, 10, 1, 16},
+		{program.Statements[1], 2, 1, 2, 14},
+		{program.Statements[1].(*ast.ExpressionStatement).Expression.(*ast.CallExpression).Arguments[1], 2, 8, 2, 13},
+		{program, 1, 1, 2, 14},
+	}
+
+	for i, tt := range tests {
+		pos, end := tt.node.Pos(), tt.node.End()
+		if pos.Line != tt.startLine || pos.Column != tt.startCol {
+			t.Errorf("tests[%d] - Pos wrong. expected=%d:%d, got=%s", i, tt.startLine, tt.startCol, pos)
+		}
+		if end.Line != tt.endLine || end.Column != tt.endCol {
+			t.Errorf("tests[%d] - End wrong. expected=%d:%d, got=%s", i, tt.endLine, tt.endCol, end)
+		}
+	}
+}
+
+func TestCommentsAreKept(t *testing.T) {
+	input := `rama x = 10;
+rama y = 20;
+vadah(x + y); // 30`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 3 {
+		t.Fatalf("program.Statements does not contain 3 statements. got=%d", len(program.Statements))
+	}
+	if len(program.Comments) != 1 {
+		t.Fatalf("program.Comments does not contain 1 comment. got=%d", len(program.Comments))
+	}
+	if program.Comments[0].Text != "// 30" {
+		t.Errorf("comment text wrong. got=%q", program.Comments[0].Text)
+	}
+	if program.Comments[0].Pos().Line != 3 {
+		t.Errorf("comment line wrong. got=%d", program.Comments[0].Pos().Line)
+	}
+}
+
+func TestLexerErrorsAreReported(t *testing.T) {
+	testParseError(t, "rama x = 1; /* oops", "1:13: unterminated block comment (add a closing */)")
+}
+
+func TestInterpolatedStringParsing(t *testing.T) {
+	input := `"Namaste, ${name}, you have ${dairghya(items) + 1} items"`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	str, ok := stmt.Expression.(*ast.InterpolatedString)
+	if !ok {
+		t.Fatalf("exp not *ast.InterpolatedString. got=%T", stmt.Expression)
+	}
+
+	if len(str.Parts) != 5 {
+		t.Fatalf("wrong number of parts. want 5, got=%d", len(str.Parts))
+	}
+
+	testIdentifier(t, str.Parts[1], "name")
+	if _, ok := str.Parts[3].(*ast.InfixExpression); !ok {
+		t.Errorf("parts[3] not *ast.InfixExpression. got=%T", str.Parts[3])
+	}
+
+	expected := `"Namaste, ${name}, you have ${(dairghya(items) + 1)} items"`
+	if str.String() != expected {
+		t.Errorf("String() wrong. expected=%q, got=%q", expected, str.String())
+	}
+
+	if str.End().Column != len(input)+1 {
+		t.Errorf("End() wrong. expected column %d, got=%d", len(input)+1, str.End().Column)
+	}
+}
+
+func TestInterpolatedStringErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a ${} b"`, "1:6: empty ${} in string"},
+		{`"a ${x y} b"`, "1:8: Expected next token to be VAKYA_TAIL, instead got IDENT"},
+	}
+
+	for _, tt := range tests {
+		testParseError(t, tt.input, tt.expected)
+	}
+}
+
+func TestLexerDiagnosticsReplaceIllegalErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{
+			"rama x = 5 @ 3;",
+			[]string{"1:12: unexpected character '@' (remove it)"},
+		},
+		{
+			"yadi (a & b) { 1 }",
+			[]string{"1:9: unexpected character '&' (did you mean &&?)"},
+		},
+		{
+			"rama n = 0b12;\nrama m = #;",
+			[]string{
+				"1:10: invalid number 0b12 (binary literals use the digits 0 and 1)",
+				"2:10: unexpected character '#' (comments start with //)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) < len(tt.expected) {
+			t.Errorf("%q: expected %d errors, got=%v", tt.input, len(tt.expected), errors)
+			continue
+		}
+		for i, want := range tt.expected {
+			if errors[i] != want {
+				t.Errorf("%q: errors[%d] wrong. expected=%q, got=%q", tt.input, i, want, errors[i])
+			}
+		}
+		for _, msg := range errors {
+			if strings.Contains(msg, "ILLEGAL") {
+				t.Errorf("%q: parser complained about ILLEGAL: %q", tt.input, msg)
+			}
+		}
+	}
+}
+
+func TestAssignExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"i = i + 1;", "(i = (i + 1))"},
+		{"a = b = 3", "(a = (b = 3))"},
+		{"x = y == z", "(x = (y == z))"},
+		{"f(x = 2)", "f((x = 2))"},
+		{"xs[i] = 1", "((xs[i]) = 1)"},
+		{`h["a"]["b"] = c = 2`, "(((h[a])[b]) = (c = 2))"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if _, ok := stmt.Expression.(*ast.CallExpression); !ok {
+			assign, ok := stmt.Expression.(*ast.AssignExpression)
+			if !ok {
+				t.Fatalf("exp not *ast.AssignExpression. got=%T", stmt.Expression)
+			}
+			switch assign.Target.(type) {
+			case *ast.Identifier, *ast.IndexExpression:
+			default:
+				t.Errorf("assign.Target not *ast.Identifier or *ast.IndexExpression. got=%T", assign.Target)
+			}
+		}
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidAssignTarget(t *testing.T) {
+	testParseError(t, "1 + 2 = 3;", "1:7: cannot assign to (1 + 2)")
+}
+
+func TestCompoundAssignAndUpdate(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"total += x * 2;", "(total += (x * 2))"},
+		{"a -= b -= 1", "(a -= (b -= 1))"},
+		{"xs[0] *= 3", "((xs[0]) *= 3)"},
+		{`h["n"] %= 4`, "((h[n]) %= 4)"},
+		{"i++", "(i++)"},
+		{"--i", "(--i)"},
+		{"xs[i]++ + 1", "(((xs[i])++) + 1)"},
+		{"-i++", "(-(i++))"},
+		{"f(i--)", "f((i--))"},
+		{"h.port = 80", "((h.port) = 80)"},
+		{"cfg.db.hits++", "(((cfg.db).hits)++)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidUpdateTarget(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"5++;", "1:2: cannot assign to 5"},
+		{"++f(x);", "1:1: cannot assign to f(x)"},
+		{"(a + b) += 1;", "1:9: cannot assign to (a + b)"},
+		{"xs[0]() = 1;", "1:9: cannot assign to (xs[0])()"},
+		{"h.size() = 1;", "1:10: cannot assign to (h.size)()"},
+	}
+
+	for _, tt := range tests {
+		testParseError(t, tt.input, tt.expected)
+	}
+}
+
+func TestLoopControlStatements(t *testing.T) {
+	input := `chakra (satya) { yadi (x) { viram; } agla; }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	loop, ok := program.Statements[0].(*ast.ChakraStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ChakraStatement. got=%T", program.Statements[0])
+	}
+	if len(loop.Body.Statements) != 2 {
+		t.Fatalf("loop body does not contain 2 statements. got=%d", len(loop.Body.Statements))
+	}
+
+	ifExp := loop.Body.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.IfExpression)
+	if _, ok := ifExp.Consequence.Statements[0].(*ast.BreakStatement); !ok {
+		t.Errorf("consequence is not ast.BreakStatement. got=%T", ifExp.Consequence.Statements[0])
+	}
+	if _, ok := loop.Body.Statements[1].(*ast.ContinueStatement); !ok {
+		t.Errorf("loop.Body.Statements[1] is not ast.ContinueStatement. got=%T", loop.Body.Statements[1])
+	}
+	if loop.String() != "chakra(satya){ifx viram;agla;}" {
+		t.Errorf("loop.String() wrong. got=%q", loop.String())
+	}
+}
+
+func TestPratyekStatement(t *testing.T) {
+	tests := []struct {
+		input     string
+		variables []string
+		expected  string
+	}{
+		{"pratyek (x : xs) { vadha(x); }", []string{"x"}, "pratyek(x : xs){vadha(x)}"},
+		{"pratyek (k, v : h) { viram }", []string{"k", "v"}, "pratyek(k, v : h){viram;}"},
+		{"pratyek (c : pratham(words)) { agla; }", []string{"c"}, "pratyek(c : pratham(words)){agla;}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.PratyekStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.PratyekStatement. got=%T", program.Statements[0])
+		}
+		if len(stmt.Variables) != len(tt.variables) {
+			t.Fatalf("wrong number of variables. want=%d, got=%d", len(tt.variables), len(stmt.Variables))
+		}
+		for i, name := range tt.variables {
+			testIdentifier(t, stmt.Variables[i], name)
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("stmt.String() wrong. expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestLoopControlOutsideLoop(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"viram;", "1:1: viram outside of a loop"},
+		{"yadi (x) { agla }", "1:12: agla outside of a loop"},
+		{"chakra (x) { kriya() { viram; } }", "1:24: viram outside of a loop"},
+		{"pratyek (x : xs) {}; agla;", "1:22: agla outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		testParseError(t, tt.input, tt.expected)
+	}
+}
+
+func TestRangeExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1..10", "(1..10)"},
+		{"0..<n", "(0..<n)"},
+		{"0..n - 1", "(0..(n - 1))"},
+		{"0..<10 kadam 2", "(0..<10 kadam 2)"},
+		{"10..1 kadam -1", "(10..1 kadam (-1))"},
+		{"a..b == c", "((a..b) == c)"},
+		{"pratyek (i : 1..3) {}", "pratyek(i : (1..3)){}"},
+		{"(0..<5)[2]", "((0..<5)[2])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestErrorRecovery(t *testing.T) {
+	tests := []struct {
+		input    string
+		errors   []string
+		expected string
+	}{
+		{
+			"rama = 5; rama y = ; rama z = 1; z",
+			[]string{
+				"1:6: Expected next token to be IDENT, instead got =",
+				"1:20: no prefix parse function for ; found",
+			},
+			"rama z = 1;z",
+		},
+		{
+			"rama x = (1 + 2 * ; rama y = 2;",
+			[]string{"1:19: no prefix parse function for ; found"},
+			"rama y = 2;",
+		},
+		{
+			"yadi (x { y } rama z = 1;",
+			[]string{"1:9: Expected next token to be ), instead got {"},
+			"rama z = 1;",
+		},
+		{
+			"kriya(x { daan x; }; rama a = 1;",
+			[]string{"1:9: Expected next token to be ), instead got {"},
+			"rama a = 1;",
+		},
+		{
+			"[1, 2 rama x = 3;",
+			[]string{"1:7: Expected next token to be ], instead got RAMA"},
+			"rama x = 3;",
+		},
+		{
+			"rama f = kriya() { rama = 1; x; rama b = ; }; f(1)",
+			[]string{
+				"1:25: Expected next token to be IDENT, instead got =",
+				"1:42: no prefix parse function for ; found",
+			},
+			"rama f = kriya()x;f(1)",
+		},
+		{
+			`rama h = {"a" 1}; milana (h) { [a, b => 1 }; rama y = 2;`,
+			[]string{
+				"1:15: Expected next token to be :, instead got INT",
+				"1:38: Expected next token to be ], instead got =>",
+			},
+			"rama y = 2;",
+		},
+		{
+			"kriya() { x",
+			[]string{"1:12: Expected next token to be }, instead got EOF"},
+			"",
+		},
+		{
+			"rama x = @; rama y = 2;",
+			[]string{"1:10: unexpected character '@' (remove it)"},
+			"rama y = 2;",
+		},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+
+		errors := p.Errors()
+		if len(errors) != len(tt.errors) {
+			t.Errorf("%q: wrong number of errors. expected=%q, got=%q", tt.input, tt.errors, errors)
+			continue
+		}
+		for i, want := range tt.errors {
+			if errors[i] != want {
+				t.Errorf("%q: errors[%d] wrong. expected=%q, got=%q", tt.input, i, want, errors[i])
+			}
+		}
+		if program.String() != tt.expected {
+			t.Errorf("%q: program wrong. expected=%q, got=%q", tt.input, tt.expected, program.String())
+		}
+	}
+}
+
+func TestReturnWithoutSemicolon(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"daan 5", "daan 5;"},
+		{"kriya() { daan x }", "kriya()daan x;"},
+		{"kriya() { daan }; daan;", "kriya()daan ;daan ;"},
+		{"daan\nrama x = 1;", "daan ;rama x = 1;"},
+		{"chakra (x) { daan\nviram; }", "chakra(x){daan ;viram;}"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	l := lexer.NewFile("main.ga", "rama x = @;\nyadi (x { 1 }")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.ParseErrors()
+	if len(errors) != 2 {
+		t.Fatalf("wrong number of errors. got=%v", p.Errors())
+	}
+
+	lexErr := errors[0]
+	if lexErr.Pos.String() != "main.ga:1:10" {
+		t.Errorf("lexer error wrong. got=%+v", lexErr)
+	}
+	if lexErr.Message != "unexpected character '@' (remove it)" {
+		t.Errorf("lexer error message wrong. got=%q", lexErr.Message)
+	}
+
+	parseErr := errors[1]
+	if parseErr.Pos.String() != "main.ga:2:9" {
+		t.Errorf("parse error wrong. got=%+v", parseErr)
+	}
+	if len(parseErr.Expected) != 1 || parseErr.Expected[0] != token.RPAREN {
+		t.Errorf("parse error expected wrong. got=%v", parseErr.Expected)
+	}
+	if parseErr.Found.Type != token.LBRACE || parseErr.Found.Literal != "{" {
+		t.Errorf("parse error found wrong. got=%+v", parseErr.Found)
+	}
+
+	for i, msg := range p.Errors() {
+		if msg != errors[i].String() {
+			t.Errorf("Errors()[%d] = %q, want %q", i, msg, errors[i].String())
+		}
+	}
+	if p.Errors()[1] != "main.ga:2:9: Expected next token to be ), instead got {" {
+		t.Errorf("wrong formatted error. got=%q", p.Errors()[1])
+	}
+}
+
+func TestMatchExpression(t *testing.T) {
+	input := `milana (x) {
+		1 => "one",
+		-2.5 => "neg",
+		[a, _] yadi a > 0 => a,
+		{"k": v} => v,
+		_ => "other",
+	}`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	stmt := program.Statements[0].(*ast.ExpressionStatement)
+	match, ok := stmt.Expression.(*ast.MatchExpression)
+	if !ok {
+		t.Fatalf("stmt.Expression is not ast.MatchExpression. got=%T", stmt.Expression)
+	}
+
+	testIdentifier(t, match.Subject, "x")
+
+	if len(match.Arms) != 5 {
+		t.Fatalf("match.Arms does not contain 5 arms. got=%d", len(match.Arms))
+	}
+
+	testLiteralExpression(t, match.Arms[0].Pattern, 1)
+	if _, ok := match.Arms[2].Pattern.(*ast.ArrayLiteral); !ok {
+		t.Errorf("arm 2 pattern is not ast.ArrayLiteral. got=%T", match.Arms[2].Pattern)
+	}
+	testInfixExpression(t, match.Arms[2].Guard, "a", ">", 0)
+	if _, ok := match.Arms[3].Pattern.(*ast.HashLiteral); !ok {
+		t.Errorf("arm 3 pattern is not ast.HashLiteral. got=%T", match.Arms[3].Pattern)
+	}
+	testIdentifier(t, match.Arms[4].Pattern, "_")
+
+	expected := `milanax { 1 => one, (-2.5) => neg, [a, _] yadi (a > 0) => a, {k:v} => v, _ => other }`
+	if match.String() != expected {
+		t.Errorf("match.String() wrong. expected=%q, got=%q", expected, match.String())
+	}
+}
+
+func TestParenthesesInMatchArms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"milana (n) { x yadi (x > 1) => x }", "milanan { x yadi (x > 1) => x }"},
+		{"milana (n) { (1) => x }", "milanan { 1 => x }"},
+		{"milana (n) { (1) => x, _ => (y) => y }", "milanan { 1 => x, _ => (y) => y }"},
+		{"milana (n) { [a, _] yadi (a > 0) => a, _ => (y) => y }", "milanan { [a, _] yadi (a > 0) => a, _ => (y) => y }"},
+		{"milana (n) { x yadi any(xs, (v) => v > x) => x }", "milanan { x yadi any(xs, (v) => (v > x)) => x }"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidPatterns(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"milana (x) { a + 1 => 2 }", "1:14: invalid pattern (a + 1)"},
+		{"milana (x) { [f(1)] => 2 }", "1:15: invalid pattern f(1)"},
+		{"milana (x) { {k: 1} => 2 }", "1:15: hash pattern keys must be literals, got k"},
+		{"milana (x) { 1 2 }", "1:16: Expected next token to be =>, instead got INT"},
+		{"milana (x) { 1 => 2 3 => 4 }", "1:21: Expected next token to be ,, instead got INT"},
+	}
+
+	for _, tt := range tests {
+		testParseError(t, tt.input, tt.expected)
+	}
+}
+
+func TestDestructuringRama(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"rama [x, y] = f();", "rama [x, y] = f();"},
+		{`rama {"name": n} = person;`, "rama {name:n} = person;"},
+		{`rama {"name": n, "age": a} = person;`, "rama {name:n, age:a} = person;"},
+		{"rama [head, ...tail] = xs;", "rama [head, ...tail] = xs;"},
+		{"rama [[a, _], ...rest] = pairs", "rama [[a, _], ...rest] = pairs;"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.RamaStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.RamaStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Name != nil || stmt.Pattern == nil {
+			t.Errorf("stmt is not destructuring. got Name=%v Pattern=%v", stmt.Name, stmt.Pattern)
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestInvalidDestructuring(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"rama [x, f(y)] = z;", "1:10: invalid pattern f(y)"},
+		{"rama [...a, b] = z;", "1:7: ...a must be the last element"},
+		{"rama [x] y;", "1:10: Expected next token to be =, instead got IDENT"},
+		{"rama x = ...y;", "1:10: ... is only allowed in patterns"},
+		{"rama [...1] = z;", "1:10: Expected next token to be IDENT, instead got INT"},
+	}
+
+	for _, tt := range tests {
+		testParseError(t, tt.input, tt.expected)
+	}
+}
+
+func TestDefaultAndRestParameters(t *testing.T) {
+	tests := []struct {
+		input        string
+		expected     string
+		expectedName string
+	}{
+		{"kriya(a, b = 10) { a + b }", "kriya(a, b = 10)(a + b)", ""},
+		{"kriya(...rest) { rest }", "kriya(...rest)rest", ""},
+		{"rama f = kriya(a, b = a * 2, ...rest) { a };", "rama f = kriya(a, b = (a * 2), ...rest)a;", "f"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+
+		var function *ast.FunctionLiteral
+		switch stmt := program.Statements[0].(type) {
+		case *ast.ExpressionStatement:
+			function = stmt.Expression.(*ast.FunctionLiteral)
+		case *ast.RamaStatement:
+			function = stmt.Value.(*ast.FunctionLiteral)
+		}
+		if function.Name != tt.expectedName {
+			t.Errorf("function.Name wrong. expected=%q, got=%q", tt.expectedName, function.Name)
+		}
+	}
+}
+
+func TestInvalidParameters(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"kriya(...rest, a) { a }", "1:10: ...rest must be the last parameter"},
+		{"kriya(a = 1, b) { b }", "1:14: parameter b needs a default value, it follows one that has one"},
+		{"kriya(1) { 1 }", "1:7: Expected next token to be IDENT, instead got INT"},
+		{"kriya(a b) { a }", "1:9: Expected next token to be ), instead got IDENT"},
+	}
+
+	for _, tt := range tests {
+		testParseError(t, tt.input, tt.expected)
+	}
+}
+
+func TestSliceExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"xs[1:3]", "(xs[1:3])"},
+		{"xs[:n]", "(xs[:n])"},
+		{"xs[-2:]", "(xs[(-2):])"},
+		{"xs[:]", "(xs[:])"},
+		{"s[i + 1:dairghya(s)][0]", "((s[(i + 1):dairghya(s)])[0])"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, stmt.Expression.String())
+		}
+	}
+
+	testParseError(t, "xs[1:2:3]", "1:7: Expected next token to be ], instead got :")
+}
+
+func TestMemberExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"cfg.db.host", "((cfg.db).host)"},
+		{"arr.push(4)", "(arr.push)(4)"},
+		{"-a.len() * 2", "((-(a.len)()) * 2)"},
+		{"xs[0].name", "((xs[0]).name)"},
+		{`"abc".len()`, "(abc.len)()"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+
+	testParseError(t, "cfg.1", "1:5: Expected next token to be IDENT, instead got INT")
+}
+
+func TestArrowFunction(t *testing.T) {
+	tests := []struct {
+		input          string
+		expectedParams []string
+		expected       string
+	}{
+		{"(x) => x * 2", []string{"x"}, "(x) => (x * 2)"},
+		{"(a, b) => { daan a + b; }", []string{"a", "b"}, "(a, b) => daan (a + b);"},
+		{"() => 1", []string{}, "() => 1"},
+		{"(a, b = (a + 1), ...r) => r", []string{"a", "b"}, "(a, b = (a + 1), ...r) => r"},
+		{"(x) => (y) => x + y", []string{"x"}, "(x) => (y) => (x + y)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt := program.Statements[0].(*ast.ExpressionStatement)
+		function, ok := stmt.Expression.(*ast.FunctionLiteral)
+		if !ok {
+			t.Fatalf("stmt.Expression is not ast.FunctionLiteral. got=%T", stmt.Expression)
+		}
+
+		if len(function.Parameters) != len(tt.expectedParams) {
+			t.Errorf("length parameters wrong. want %d, got=%d", len(tt.expectedParams), len(function.Parameters))
+		}
+		for i, ident := range tt.expectedParams {
+			testLiteralExpression(t, function.Parameters[i], ident)
+		}
+
+		if function.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, function.String())
+		}
+	}
+}
+
+func TestArrowFunctionInExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"map(xs, (x) => x * 2);", "map(xs, (x) => (x * 2))"},
+		{"rama f = (x) => x;", "rama f = (x) => x;"},
+		{"(1 + 2) * 3;", "((1 + 2) * 3)"},
+		{"(f(x)) + ((y));", "(f(x) + y)"},
+		{"(a) + (b) => b;", "(a + (b) => b)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if program.String() != tt.expected {
+			t.Errorf("expected=%q, got=%q", tt.expected, program.String())
+		}
+	}
+}
+
+func TestInvalidArrowFunction(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"(x + 1) => x", "1:4: Expected next token to be ), instead got +"},
+		{"(1) => 1", "1:2: Expected next token to be IDENT, instead got INT"},
+		{"(x) =>", "1:7: no prefix parse function for EOF found"},
+		{"chakra (satya) { rama f = () => { viram; }; }", "1:35: viram outside of a loop"},
+	}
+
+	for _, tt := range tests {
+		testParseError(t, tt.input, tt.expected)
+	}
+}
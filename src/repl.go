@@ -58,13 +58,14 @@ func runFile(filename string) {
 		os.Exit(1)
 	}
 
-	content, err := os.ReadFile(filename)
+	file, err := os.Open(filename)
 	if err != nil {
 		fmt.Printf("❌ Error reading file '%s': %s\n", filename, err)
 		os.Exit(1)
 	}
+	defer file.Close()
 
-	l := lexer.New(string(content))
+	l := lexer.NewFileReader(filename, file)
 	p := parser.New(l)
 	program := p.ParseProgram()
 
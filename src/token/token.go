@@ -1,10 +1,40 @@
 package token
 
+import "fmt"
+
 type TokenType string
 
 type Token struct {
 	Type    TokenType
 	Literal string
+	Pos     Position // where the token starts
+	End     Position // just past the last character of the token
+}
+
+// Position is a location in the source. Line and Column start at 1,
+// Offset is the byte offset from the start of the input.
+type Position struct {
+	Filename string
+	Offset   int
+	Line     int
+	Column   int
+}
+
+// IsValid reports whether the position was set by the lexer.
+func (p Position) IsValid() bool { return p.Line > 0 }
+
+func (p Position) String() string {
+	s := p.Filename
+	if p.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d:%d", p.Line, p.Column)
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
 }
 
 // Following are the different token types
@@ -12,10 +42,12 @@ type Token struct {
 const (
 	ILLEGAL = "ILLEGAL"
 	EOF     = "EOF"
+	COMMENT = "COMMENT" // never returned by the lexer, kept for the AST
 
 	// Identifiers + literals
 	IDENT = "IDENT" // add, foobar, x, y, ...
 	INT   = "INT"   // 1343456
+	FLOAT = "FLOAT" // 3.14, 1e-9
 
 	// Operators
 	ASSIGN   = "="
@@ -24,13 +56,32 @@ const (
 	BANG     = "!"
 	ASTERISK = "*"
 	SLASH    = "/"
+	PERCENT  = "%"
 
-	LT = "<"
-	GT = ">"
+	LT    = "<"
+	GT    = ">"
+	LT_EQ = "<="
+	GT_EQ = ">="
 
 	EQ     = "=="
 	NOT_EQ = "!="
 
+	AND = "&&"
+	OR  = "||"
+
+	PLUS_ASSIGN     = "+="
+	MINUS_ASSIGN    = "-="
+	ASTERISK_ASSIGN = "*="
+	SLASH_ASSIGN    = "/="
+	PERCENT_ASSIGN  = "%="
+	INCREMENT       = "++"
+	DECREMENT       = "--"
+
+	RANGE    = ".."
+	RANGE_LT = "..<"
+	ARROW    = "=>"
+	ELLIPSIS = "..."
+
 	// Delimiters
 	COMMA     = ","
 	SEMICOLON = ";"
@@ -41,6 +92,7 @@ const (
 	LBRACKET  = "["
 	RBRACKET  = "]"
 	COLON     = ":"
+	DOT       = "."
 
 	// Keywords in Sankrit (for the most part)
 	KRIYA   = "KRIYA"
@@ -52,6 +104,16 @@ const (
 	DAAN    = "DAAN"
 	VAKYA   = "VAKYA"
 	CHAKRA  = "CHAKRA"
+	VIRAM   = "VIRAM" // break
+	AGLA    = "AGLA"  // continue
+	PRATYEK = "PRATYEK"
+	KADAM   = "KADAM" // the step of a range
+	MILANA  = "MILANA"
+
+	// Pieces of an interpolated string "head ${a} middle ${b} tail"
+	VAKYA_HEAD   = "VAKYA_HEAD"
+	VAKYA_MIDDLE = "VAKYA_MIDDLE"
+	VAKYA_TAIL   = "VAKYA_TAIL"
 )
 
 var keywords = map[string]TokenType{
@@ -63,6 +125,26 @@ var keywords = map[string]TokenType{
 	"satya":   SATYA,
 	"asatya":  ASATYA,
 	"chakra":  CHAKRA,
+	"viram":   VIRAM,
+	"agla":    AGLA,
+	"pratyek": PRATYEK,
+	"kadam":   KADAM,
+	"milana":  MILANA,
+
+	// Devanagari spellings
+	"क्रिया":   KRIYA,
+	"राम":      RAMA,
+	"यदि":      YADI,
+	"दान":      DAAN,
+	"अन्यथा":   ANYATHA,
+	"सत्य":     SATYA,
+	"असत्य":    ASATYA,
+	"चक्र":     CHAKRA,
+	"विराम":    VIRAM,
+	"अगला":     AGLA,
+	"प्रत्येक": PRATYEK,
+	"कदम":      KADAM,
+	"मिलान":    MILANA,
 }
 
 func LookupIdent(ident string) TokenType {